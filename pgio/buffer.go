@@ -45,6 +45,11 @@ func (buf *Buffer) ShiftBytes(length int) (value []byte, err error) {
 	return
 }
 
+func (buf *Buffer) ShiftBytesInto(dst []byte, length int) (value []byte, err error) {
+	value, buf.data, err = ShiftBytesInto(dst, buf.data, length)
+	return
+}
+
 func (buf *Buffer) ShiftInt8() (value int8, err error) {
 	value, buf.data, err = ShiftInt8(buf.data)
 	return
@@ -24,6 +24,21 @@ func ShiftBytes(b []byte, length int) ([]byte, []byte, error) {
 	return output, b[length:], nil
 }
 
+// ShiftBytesInto is like ShiftBytes, but copies into dst when its capacity
+// allows instead of allocating.
+func ShiftBytesInto(dst, b []byte, length int) ([]byte, []byte, error) {
+	if len(b) < length {
+		return nil, b, ErrValueUnderflow
+	}
+
+	if dst == nil || cap(dst) < length {
+		dst = make([]byte, length)
+	}
+	dst = dst[:length]
+	copy(dst, b[:length])
+	return dst, b[length:], nil
+}
+
 func ShiftInt8(b []byte) (int8, []byte, error) {
 	v, b, err := ShiftByte(b)
 	if err != nil {
@@ -71,7 +86,7 @@ func AppendInt8(b []byte, i ...int8) []byte {
 }
 
 func AppendInt16(b []byte, i ...int16) []byte {
-	b = slices.Grow(b, len(i))
+	b = slices.Grow(b, 2*len(i))
 	for _, v := range i {
 		b = binary.BigEndian.AppendUint16(b, uint16(v))
 	}
@@ -79,7 +94,7 @@ func AppendInt16(b []byte, i ...int16) []byte {
 }
 
 func AppendInt32(b []byte, i ...int32) []byte {
-	b = slices.Grow(b, len(i))
+	b = slices.Grow(b, 4*len(i))
 	for _, v := range i {
 		b = binary.BigEndian.AppendUint32(b, uint32(v))
 	}
@@ -87,7 +102,7 @@ func AppendInt32(b []byte, i ...int32) []byte {
 }
 
 func AppendInt64(b []byte, i ...int64) []byte {
-	b = slices.Grow(b, len(i))
+	b = slices.Grow(b, 8*len(i))
 	for _, v := range i {
 		b = binary.BigEndian.AppendUint64(b, uint64(v))
 	}
@@ -0,0 +1,144 @@
+package pgio_test
+
+import (
+	"gopsql/pgio"
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/require"
+)
+
+var int16Boundaries = []int16{
+	math.MinInt16,
+	math.MinInt16 + 1,
+	-256,
+	-255,
+	-1,
+	0,
+	1,
+	255,
+	256,
+	math.MaxInt16 - 1,
+	math.MaxInt16,
+}
+
+var int32Boundaries = []int32{
+	math.MinInt32,
+	math.MinInt32 + 1,
+	math.MinInt16,
+	-65536,
+	-256,
+	-1,
+	0,
+	1,
+	255,
+	65535,
+	math.MaxInt16,
+	math.MaxInt32 - 1,
+	math.MaxInt32,
+}
+
+func TestInt16RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, want := range int16Boundaries {
+		b := pgio.AppendInt16(nil, want)
+		require.Len(t, b, 2)
+
+		got, rest, err := pgio.ShiftInt16(b)
+		require.NoError(t, err)
+		require.Empty(t, rest)
+		require.Equal(t, want, got)
+	}
+
+	err := quick.Check(func(want int16) bool {
+		got, rest, err := pgio.ShiftInt16(pgio.AppendInt16(nil, want))
+		return err == nil && len(rest) == 0 && got == want
+	}, nil)
+	require.NoError(t, err)
+}
+
+func TestInt32RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, want := range int32Boundaries {
+		b := pgio.AppendInt32(nil, want)
+		require.Len(t, b, 4)
+
+		got, rest, err := pgio.ShiftInt32(b)
+		require.NoError(t, err)
+		require.Empty(t, rest)
+		require.Equal(t, want, got)
+	}
+
+	err := quick.Check(func(want int32) bool {
+		got, rest, err := pgio.ShiftInt32(pgio.AppendInt32(nil, want))
+		return err == nil && len(rest) == 0 && got == want
+	}, nil)
+	require.NoError(t, err)
+}
+
+func TestBufferIntRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendInt16(int16Boundaries...)
+	buf.AppendInt32(int32Boundaries...)
+
+	for _, want := range int16Boundaries {
+		got, err := buf.ShiftInt16()
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	for _, want := range int32Boundaries {
+		got, err := buf.ShiftInt32()
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+	require.Zero(t, buf.Len())
+}
+
+func TestShiftIntUnderflow(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := pgio.ShiftInt16([]byte{0})
+	require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+
+	_, _, err = pgio.ShiftInt32([]byte{0, 0, 0})
+	require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+}
+
+func TestAppendIntGrowth(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts differ under the race detector")
+	}
+
+	values := make([]int32, 64)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = pgio.AppendInt32(nil, values...)
+	})
+	require.Equal(t, float64(1), allocs)
+}
+
+func TestShiftBytesInto(t *testing.T) {
+	t.Parallel()
+
+	dst := make([]byte, 2, 8)
+
+	value, rest, err := pgio.ShiftBytesInto(dst, []byte("abcdef"), 4)
+	require.NoError(t, err)
+	require.Equal(t, []byte("abcd"), value)
+	require.Equal(t, []byte("ef"), rest)
+	require.Same(t, &dst[:1][0], &value[0])
+
+	value, _, err = pgio.ShiftBytesInto(nil, []byte("ab"), 0)
+	require.NoError(t, err)
+	require.NotNil(t, value)
+	require.Empty(t, value)
+
+	_, _, err = pgio.ShiftBytesInto(dst, []byte("ab"), 3)
+	require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+}
@@ -8,4 +8,6 @@ var (
 	ErrUnknownMessageType = errors.New("unknown message type")
 	ErrUnknownAuthType    = errors.New("unknown authentication type")
 	ErrUnknownCode        = errors.New("unknown code")
+	ErrEmbeddedNull       = errors.New("string contains a null byte")
+	ErrInvalidValue       = errors.New("invalid value")
 )
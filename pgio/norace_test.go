@@ -0,0 +1,5 @@
+//go:build !race
+
+package pgio_test
+
+const raceEnabled = false
@@ -0,0 +1,7 @@
+//go:build race
+
+package pgio_test
+
+// raceEnabled reports whether the race detector is on. Its instrumentation
+// allocates, so exact allocation counts are only checked without it.
+const raceEnabled = true
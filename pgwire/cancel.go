@@ -0,0 +1,52 @@
+package pgwire
+
+import (
+	"context"
+	"io"
+	"net"
+)
+
+// Cancel asks the server at addr to cancel the query running on the
+// connection that received key in its BackendKeyData. Cancellation needs a
+// connection of its own: Cancel dials addr over TCP and sends a
+// CancelRequest as done by SendCancelRequest. The server does not report
+// whether anything was cancelled. ctx also bounds the wait for the server
+// to close the connection.
+func Cancel(ctx context.Context, addr string, key *MsgBackendKeyData) error {
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+
+	err = SendCancelRequest(conn, key)
+	if !stop() {
+		return ctx.Err()
+	}
+	return err
+}
+
+// SendCancelRequest sends a CancelRequest for key on conn, a new connection
+// to the server, then waits for the server to close it and closes it too.
+// Waiting keeps the request from racing queries sent after it returns.
+func SendCancelRequest(conn io.ReadWriteCloser, key *MsgBackendKeyData) error {
+	defer conn.Close()
+
+	b, err := (&MsgCancelRequest{ProcessID: key.ProcessID, SecretKey: key.SecretKey}).AppendBinary(nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Write(b)
+	if err != nil {
+		return err
+	}
+
+	// The server sends nothing; any error reading here only means the
+	// connection is already gone.
+	_, _ = io.Copy(io.Discard, conn)
+	return nil
+}
@@ -0,0 +1,104 @@
+package pgwire_test
+
+import (
+	"bytes"
+	"context"
+	"gopsql/pgwire"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancel(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	received := make(chan pgwire.Frontend, 1)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer conn.Close()
+
+		m, err := pgwire.NewServerConn(conn).ReceiveStartup()
+		if err != nil {
+			close(received)
+			return
+		}
+		received <- m
+	}()
+
+	key := &pgwire.MsgBackendKeyData{ProcessID: 4321, SecretKey: []byte{1, 2, 3, 4}}
+
+	err = pgwire.Cancel(context.Background(), l.Addr().String(), key)
+	require.NoError(t, err)
+	require.Equal(t, &pgwire.MsgCancelRequest{ProcessID: 4321, SecretKey: []byte{1, 2, 3, 4}}, <-received)
+}
+
+func TestCancelPeerNeverCloses(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// Hold the connection open until the test ends.
+		<-done
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	key := &pgwire.MsgBackendKeyData{ProcessID: 4321, SecretKey: []byte{1, 2, 3, 4}}
+
+	err = pgwire.Cancel(ctx, l.Addr().String(), key)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSendCancelRequest(t *testing.T) {
+	t.Parallel()
+
+	s := &closingScript{script: newScript(t)}
+
+	err := pgwire.SendCancelRequest(s, &pgwire.MsgBackendKeyData{ProcessID: 7, SecretKey: []byte{0, 0, 0, 9}})
+	require.NoError(t, err)
+	require.True(t, s.closed)
+	require.Equal(t, []byte{
+		0, 0, 0, 16, // length
+		0x04, 0xd2, 0x16, 0x2e, // cancel request code
+		0, 0, 0, 7, // process id
+		0, 0, 0, 9, // secret key
+	}, s.out.Bytes())
+}
+
+func TestSendCancelRequestLongKey(t *testing.T) {
+	t.Parallel()
+
+	s := &closingScript{script: newScript(t)}
+	key := &pgwire.MsgBackendKeyData{ProcessID: 7, SecretKey: bytes.Repeat([]byte{0xab}, 32)}
+
+	err := pgwire.SendCancelRequest(s, key)
+	require.NoError(t, err)
+
+	var m pgwire.MsgCancelRequest
+	require.NoError(t, m.UnmarshalBinary(s.out.Bytes()))
+	require.Equal(t, key.SecretKey, m.SecretKey)
+}
@@ -0,0 +1,69 @@
+package pgwire
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CommandTag is the parsed form of a CommandComplete tag such as
+// "INSERT 0 42", "UPDATE 7" or "CREATE TABLE".
+type CommandTag struct {
+	// Command is the tag without its counts, such as "INSERT" or
+	// "CREATE TABLE".
+	Command string
+
+	// Rows is the number of rows the command processed. HasRows is false
+	// for commands that report no count.
+	Rows    int64
+	HasRows bool
+
+	// OID is the legacy object ID field of an INSERT tag, always zero on
+	// current servers.
+	OID uint32
+}
+
+// ParseCommandTag splits tag into its command and counts. It never fails:
+// a trailing number is taken as the row count whatever the command, so tags
+// added in later server versions are still understood, and anything else is
+// kept as the command.
+func ParseCommandTag(tag string) CommandTag {
+	x := CommandTag{Command: tag}
+
+	i := strings.LastIndexByte(tag, ' ')
+	if i < 0 {
+		return x
+	}
+
+	rows, err := strconv.ParseInt(tag[i+1:], 10, 64)
+	if err != nil {
+		return x
+	}
+
+	x.Command, x.Rows, x.HasRows = tag[:i], rows, true
+
+	if verb, oid, ok := strings.Cut(x.Command, " "); ok && verb == "INSERT" {
+		n, err := strconv.ParseUint(oid, 10, 32)
+		if err == nil {
+			x.Command, x.OID = verb, uint32(n)
+		}
+	}
+	return x
+}
+
+// String formats x as the server would, so that a server implementation can
+// build tags: INSERT always carries the OID field before the row count.
+func (x CommandTag) String() string {
+	if !x.HasRows {
+		return x.Command
+	}
+
+	if x.Command == "INSERT" {
+		return x.Command + " " + strconv.FormatUint(uint64(x.OID), 10) + " " + strconv.FormatInt(x.Rows, 10)
+	}
+	return x.Command + " " + strconv.FormatInt(x.Rows, 10)
+}
+
+// CommandTag parses the tag of x.
+func (x *MsgCommandComplete) CommandTag() CommandTag {
+	return ParseCommandTag(x.Tag)
+}
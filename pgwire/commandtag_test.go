@@ -0,0 +1,50 @@
+package pgwire_test
+
+import (
+	"gopsql/pgwire"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommandTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		tag  string
+		want pgwire.CommandTag
+	}{
+		{"INSERT 0 42", pgwire.CommandTag{Command: "INSERT", Rows: 42, HasRows: true}},
+		{"INSERT 16384 1", pgwire.CommandTag{Command: "INSERT", Rows: 1, HasRows: true, OID: 16384}},
+		{"UPDATE 7", pgwire.CommandTag{Command: "UPDATE", Rows: 7, HasRows: true}},
+		{"DELETE 0", pgwire.CommandTag{Command: "DELETE", Rows: 0, HasRows: true}},
+		{"SELECT 100", pgwire.CommandTag{Command: "SELECT", Rows: 100, HasRows: true}},
+		{"COPY 1000", pgwire.CommandTag{Command: "COPY", Rows: 1000, HasRows: true}},
+		{"MERGE 3", pgwire.CommandTag{Command: "MERGE", Rows: 3, HasRows: true}},
+		{"BEGIN", pgwire.CommandTag{Command: "BEGIN"}},
+		{"CREATE TABLE", pgwire.CommandTag{Command: "CREATE TABLE"}},
+		{"FUTURE VERB 12", pgwire.CommandTag{Command: "FUTURE VERB", Rows: 12, HasRows: true}},
+		{"", pgwire.CommandTag{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			t.Parallel()
+
+			got := pgwire.ParseCommandTag(tt.tag)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.tag, got.String())
+
+			m := &pgwire.MsgCommandComplete{Tag: tt.tag}
+			require.Equal(t, tt.want, m.CommandTag())
+		})
+	}
+}
+
+func TestCommandTagString(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "INSERT 0 5", pgwire.CommandTag{Command: "INSERT", Rows: 5, HasRows: true}.String())
+	require.Equal(t, "SELECT 0", pgwire.CommandTag{Command: "SELECT", HasRows: true}.String())
+	require.Equal(t, "COMMIT", pgwire.CommandTag{Command: "COMMIT"}.String())
+}
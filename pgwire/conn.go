@@ -0,0 +1,424 @@
+package pgwire
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Role is the side of the protocol a Conn speaks for.
+type Role int
+
+const (
+	// RoleClient sends frontend messages and receives backend messages.
+	RoleClient Role = iota
+	// RoleServer sends backend messages and receives frontend messages.
+	RoleServer
+)
+
+type Conn struct {
+	rw     io.ReadWriter
+	role   Role
+	reader *MessageReader
+	buf    []byte
+
+	statementSeq uint64
+	statements   map[string]*PreparedStatement
+	txStatus     TransactionStatusKind
+
+	authState AuthState
+	authReq   Backend
+
+	protocolVersion ProtocolVersion
+
+	onNotice          func(*MsgNoticeResponse)
+	onParameterStatus func(*MsgParameterStatus)
+}
+
+// NewConn returns a client Conn.
+func NewConn(rw io.ReadWriter) *Conn {
+	return &Conn{
+		rw:     rw,
+		role:   RoleClient,
+		reader: NewMessageReader(rw),
+	}
+}
+
+// NewServerConn returns a Conn for the server side of the protocol, such as
+// a proxy or a mock server in tests.
+func NewServerConn(rw io.ReadWriter) *Conn {
+	return &Conn{
+		rw:     rw,
+		role:   RoleServer,
+		reader: NewMessageReader(rw),
+	}
+}
+
+func (c *Conn) Role() Role {
+	return c.role
+}
+
+// SetDeadline sets the read and write deadlines on the underlying
+// connection, such as a net.Conn or *tls.Conn. It returns os.ErrNoDeadline
+// when the connection does not support deadlines.
+func (c *Conn) SetDeadline(t time.Time) error {
+	if d, ok := c.rw.(interface{ SetDeadline(time.Time) error }); ok {
+		return d.SetDeadline(t)
+	}
+	return os.ErrNoDeadline
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	if d, ok := c.rw.(interface{ SetReadDeadline(time.Time) error }); ok {
+		return d.SetReadDeadline(t)
+	}
+	return os.ErrNoDeadline
+}
+
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	if d, ok := c.rw.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		return d.SetWriteDeadline(t)
+	}
+	return os.ErrNoDeadline
+}
+
+// NextStatementName returns a prepared statement name not previously
+// returned by this Conn, such as "stmt_1", for use with Parse, Bind,
+// Describe and Close.
+func (c *Conn) NextStatementName() string {
+	c.statementSeq++
+	return "stmt_" + strconv.FormatUint(c.statementSeq, 10)
+}
+
+// Send encodes the messages into a single buffer and writes them to the
+// underlying connection with one call to Write. A client may only send
+// frontend messages and a server only backend messages; otherwise nothing is
+// written and ErrWrongRole is returned.
+func (c *Conn) Send(msgs ...Message) error {
+	b := c.buf[:0]
+	state, req := c.authState, c.authReq
+
+	for _, m := range msgs {
+		err := c.check(m)
+		if err != nil {
+			return err
+		}
+
+		if c.role == RoleServer && m.Kind() == MessageKindAuthentication {
+			state, err = state.Next(m.(Backend))
+			if err != nil {
+				return err
+			}
+			req = m.(Backend)
+		}
+
+		b, err = m.AppendBinary(b)
+		if err != nil {
+			return err
+		}
+	}
+	c.buf = b
+	c.authState, c.authReq = state, req
+
+	_, err := c.rw.Write(b)
+	return err
+}
+
+// Receive reads the next message from the peer: a backend message for a
+// client, a frontend message for a server.
+func (c *Conn) Receive() (Message, error) {
+	if c.role == RoleServer {
+		return c.reader.ReadFrontend()
+	}
+	m, err := c.reader.ReadBackend()
+	if err != nil {
+		return nil, err
+	}
+	c.observe(m)
+	return m, nil
+}
+
+// ReceiveStartup reads the packet that opens a connection, which has no kind
+// byte. Only a server may call it.
+func (c *Conn) ReceiveStartup() (Frontend, error) {
+	if c.role != RoleServer {
+		return nil, fmt.Errorf("%w: client cannot receive startup packets", ErrWrongRole)
+	}
+	return c.reader.ReadStartup()
+}
+
+// ReceiveAuthResponse reads the client's reply to the authentication request
+// req, or to the last one sent on c when req is nil. Only a server may call
+// it.
+func (c *Conn) ReceiveAuthResponse(req Backend) (Frontend, error) {
+	if c.role != RoleServer {
+		return nil, fmt.Errorf("%w: client cannot receive authentication responses", ErrWrongRole)
+	}
+
+	if req == nil {
+		req = c.authReq
+	}
+
+	if req == nil {
+		return nil, fmt.Errorf("%w: no authentication request sent", ErrUnexpectedKind)
+	}
+	return c.reader.ReadAuthResponse(req)
+}
+
+// receiveBackend reads the next message as a client.
+func (c *Conn) receiveBackend() (Backend, error) {
+	if c.role != RoleClient {
+		return nil, fmt.Errorf("%w: server cannot receive backend messages", ErrWrongRole)
+	}
+	m, err := c.reader.ReadBackend()
+	if err != nil {
+		return nil, err
+	}
+	c.observe(m)
+	return m, nil
+}
+
+// observe records the transaction status carried by a ReadyForQuery.
+func (c *Conn) observe(m Backend) {
+	if m, ok := m.(*MsgReadyForQuery); ok {
+		c.txStatus = TransactionStatusKind(m.TxStatus)
+	}
+}
+
+// InTransaction reports whether the last ReadyForQuery received showed the
+// session inside a transaction block, either active or failed. It returns
+// ErrNoTxStatus when no ReadyForQuery has been received yet.
+func (c *Conn) InTransaction() (bool, error) {
+	switch c.txStatus {
+	case 0:
+		return false, ErrNoTxStatus
+	case TransactionStatusKindActive, TransactionStatusKindError:
+		return true, nil
+	}
+	return false, nil
+}
+
+func (c *Conn) check(m Message) error {
+	switch c.role {
+	case RoleClient:
+		if _, ok := m.(Frontend); !ok {
+			return fmt.Errorf("%w: client cannot send %T", ErrWrongRole, m)
+		}
+	case RoleServer:
+		if _, ok := m.(Backend); !ok {
+			return fmt.Errorf("%w: server cannot send %T", ErrWrongRole, m)
+		}
+	}
+	return nil
+}
+
+// Close sends Terminate, for a client, and then closes the underlying
+// connection when it implements io.Closer. The connection is closed even if
+// sending fails.
+func (c *Conn) Close() error {
+	var err error
+
+	if c.role == RoleClient {
+		err = c.Send(&MsgTerminate{})
+	}
+
+	if closer, ok := c.rw.(io.Closer); ok {
+		cerr := closer.Close()
+		if err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// SimpleQuery runs sql using the simple query protocol. When sql contains
+// several statements, only the result of the last one is returned.
+func (c *Conn) SimpleQuery(sql string) (*QueryResult, error) {
+	err := c.Send(&MsgQuery{Value: sql})
+	if err != nil {
+		return nil, err
+	}
+	return c.collect()
+}
+
+func (c *Conn) collect() (*QueryResult, error) {
+	var rc resultCollector
+
+	for {
+		m, err := c.receiveBackend()
+		if err != nil {
+			return nil, err
+		}
+
+		if rc.add(m) {
+			return rc.result()
+		}
+	}
+}
+
+// Exec runs sql using the extended query protocol with the unnamed statement
+// and portal. A nil parameter is sent as NULL. All parameters and result
+// columns use the text format.
+func (c *Conn) Exec(sql string, params [][]byte) (*QueryResult, error) {
+	err := c.Send(
+		&MsgParse{Query: sql},
+		&MsgBind{ParameterData: params},
+		&MsgDescribe{ObjectKind: ObjectKindPortal},
+		&MsgExecute{},
+		&MsgSync{},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return c.collect()
+}
+
+// Parse sends m followed by Flush and waits for ParseComplete. Errors are
+// handled as in Bind.
+func (c *Conn) Parse(m *MsgParse) error {
+	return c.flushAndWait(m, func(m Backend) bool {
+		_, ok := m.(*MsgParseComplete)
+		return ok
+	})
+}
+
+// Bind sends m followed by Flush and waits for BindComplete. If the server
+// rejects the bind, Bind sends Sync to end the failed extended query and
+// returns the error once the server is ready again.
+func (c *Conn) Bind(m *MsgBind) error {
+	return c.flushAndWait(m, func(m Backend) bool {
+		_, ok := m.(*MsgBindComplete)
+		return ok
+	})
+}
+
+// ClosePortal closes the named portal and waits for CloseComplete. Errors
+// are handled as in Bind.
+func (c *Conn) ClosePortal(name string) error {
+	return c.close(ObjectKindPortal, name)
+}
+
+// CloseStatement closes the named prepared statement and waits for
+// CloseComplete. Errors are handled as in Bind. The statement stays in the
+// Prepare cache unless the server confirms it is closed.
+func (c *Conn) CloseStatement(name string) error {
+	err := c.close(ObjectKindStatement, name)
+	if err != nil {
+		return err
+	}
+
+	for sql, ps := range c.statements {
+		if ps.Name == name {
+			delete(c.statements, sql)
+		}
+	}
+	return nil
+}
+
+func (c *Conn) close(kind ObjectKind, name string) error {
+	return c.flushAndWait(&MsgClose{ObjectKind: kind, ObjectName: name}, func(m Backend) bool {
+		_, ok := m.(*MsgCloseComplete)
+		return ok
+	})
+}
+
+// flushAndWait sends m followed by Flush and reads until complete reports
+// the expected reply. An ErrorResponse is returned as a *PgError after
+// recovering the connection.
+func (c *Conn) flushAndWait(m Frontend, complete func(Backend) bool) error {
+	err := c.Send(m, &MsgFlush{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		m, err := c.receiveBackend()
+		if err != nil {
+			return err
+		}
+
+		if complete(m) {
+			return nil
+		}
+
+		if m, ok := m.(*MsgErrorResponse); ok {
+			return c.recover(newPgError(m))
+		}
+	}
+}
+
+// recover sends Sync after an error in an extended query, which the server
+// answers once it has discarded the rest of the query, and returns pgErr.
+func (c *Conn) recover(pgErr *PgError) error {
+	err := c.Send(&MsgSync{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		m, err := c.receiveBackend()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := m.(*MsgReadyForQuery); ok {
+			return pgErr
+		}
+	}
+}
+
+// CallFunction invokes the function identified by oid using the fast-path
+// function call protocol. Arguments are sent in the text format and a nil
+// argument is sent as NULL. The returned result is nil when the function
+// returned NULL.
+func (c *Conn) CallFunction(oid int32, args [][]byte, resultBinary bool) ([]byte, error) {
+	resultFormat := FormatKindText
+	if resultBinary {
+		resultFormat = FormatKindBinary
+	}
+
+	err := c.Send(&MsgFunctionCall{
+		ObjectID:       oid,
+		ArgumentValues: args,
+		ResultFormat:   resultFormat,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, _, err := c.ReceiveFunctionResult()
+	return result, err
+}
+
+// ReceiveFunctionResult reads the reply to a FunctionCall: the
+// FunctionCallResponse and the ReadyForQuery that follows it. It returns the
+// result, nil when the function returned NULL, and the transaction status.
+// An ErrorResponse is returned as a *PgError once ReadyForQuery arrives.
+func (c *Conn) ReceiveFunctionResult() ([]byte, TransactionStatusKind, error) {
+	var result []byte
+	var pgErr error
+
+	for {
+		m, err := c.receiveBackend()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch m := m.(type) {
+		case *MsgFunctionCallResponse:
+			result = m.Result
+		case *MsgErrorResponse:
+			if pgErr == nil {
+				pgErr = newPgError(m)
+			}
+		case *MsgReadyForQuery:
+			txStatus := TransactionStatusKind(m.TxStatus)
+			if pgErr != nil {
+				return nil, txStatus, pgErr
+			}
+			return result, txStatus, nil
+		}
+	}
+}
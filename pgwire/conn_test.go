@@ -0,0 +1,517 @@
+package pgwire_test
+
+import (
+	"errors"
+	"gopsql/pgwire"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnSimpleQuery(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgRowDescription{
+			Names:     []string{"id"},
+			Tables:    []int32{0},
+			Columns:   []int16{0},
+			DataTypes: []int32{23},
+			Sizes:     []int16{4},
+			Modifiers: []int32{-1},
+			Formats:   []int16{0},
+		},
+		&pgwire.MsgDataRow{Columns: [][]byte{[]byte("1")}},
+		&pgwire.MsgDataRow{Columns: [][]byte{[]byte("2")}},
+		&pgwire.MsgCommandComplete{Tag: "SELECT 2"},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	result, err := c.SimpleQuery("SELECT id FROM t")
+	require.NoError(t, err)
+	require.Equal(t, []string{"id"}, result.Description.Names)
+	require.Equal(t, [][][]byte{
+		{[]byte("1")},
+		{[]byte("2")},
+	}, result.Rows)
+	require.Equal(t, "SELECT 2", result.Tag)
+
+	require.Equal(t, appendMessages(t, &pgwire.MsgQuery{Value: "SELECT id FROM t"}), s.out.Bytes())
+}
+
+func TestConnSimpleQueryError(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgErrorResponse{
+			Fields: []byte{
+				byte(pgwire.FieldKindSeverity),
+				byte(pgwire.FieldKindCode),
+				byte(pgwire.FieldKindMessage),
+			},
+			Values: []string{
+				"ERROR",
+				"42P01",
+				`relation "t" does not exist`,
+			},
+		},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	result, err := c.SimpleQuery("SELECT id FROM t")
+	require.Nil(t, result)
+
+	var pgErr *pgwire.PgError
+	require.True(t, errors.As(err, &pgErr))
+	require.Equal(t, `ERROR: relation "t" does not exist (SQLSTATE 42P01)`, pgErr.Error())
+}
+
+func TestConnExec(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgBindComplete{},
+		&pgwire.MsgRowDescription{
+			Names:     []string{"name"},
+			Tables:    []int32{0},
+			Columns:   []int16{0},
+			DataTypes: []int32{25},
+			Sizes:     []int16{-1},
+			Modifiers: []int32{-1},
+			Formats:   []int16{0},
+		},
+		&pgwire.MsgDataRow{Columns: [][]byte{[]byte("alice")}},
+		&pgwire.MsgCommandComplete{Tag: "SELECT 1"},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	params := [][]byte{[]byte("1"), nil}
+
+	result, err := c.Exec("SELECT name FROM t WHERE id = $1 OR $2 IS NULL", params)
+	require.NoError(t, err)
+	require.Equal(t, []string{"name"}, result.Description.Names)
+	require.Equal(t, [][][]byte{{[]byte("alice")}}, result.Rows)
+	require.Equal(t, "SELECT 1", result.Tag)
+
+	want := appendMessages(t,
+		&pgwire.MsgParse{Query: "SELECT name FROM t WHERE id = $1 OR $2 IS NULL"},
+		&pgwire.MsgBind{ParameterData: params},
+		&pgwire.MsgDescribe{ObjectKind: pgwire.ObjectKindPortal},
+		&pgwire.MsgExecute{},
+		&pgwire.MsgSync{},
+	)
+	require.Equal(t, want, s.out.Bytes())
+
+	var bind pgwire.MsgBind
+
+	r := pgwire.NewMessageReader(&s.out)
+	_, err = r.Next()
+	require.NoError(t, err)
+	frame, err := r.Next()
+	require.NoError(t, err)
+	require.NoError(t, bind.UnmarshalBinary(frame))
+	require.Equal(t, params, bind.ParameterData)
+}
+
+func TestConnExecError(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgErrorResponse{
+			Fields: []byte{
+				byte(pgwire.FieldKindSeverity),
+				byte(pgwire.FieldKindCode),
+				byte(pgwire.FieldKindMessage),
+			},
+			Values: []string{
+				"ERROR",
+				"42601",
+				`syntax error at or near "SELEC"`,
+			},
+		},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	result, err := c.Exec("SELEC 1", nil)
+	require.Nil(t, result)
+
+	var pgErr *pgwire.PgError
+	require.True(t, errors.As(err, &pgErr))
+	require.Equal(t, `ERROR: syntax error at or near "SELEC" (SQLSTATE 42601)`, pgErr.Error())
+}
+
+func TestConnCallFunction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Result", func(t *testing.T) {
+		s := newScript(t,
+			&pgwire.MsgFunctionCallResponse{Result: []byte{0, 0, 0, 42}},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		c := pgwire.NewConn(s)
+
+		result, err := c.CallFunction(952, [][]byte{[]byte("1"), nil}, true)
+		require.NoError(t, err)
+		require.Equal(t, []byte{0, 0, 0, 42}, result)
+
+		want := appendMessages(t, &pgwire.MsgFunctionCall{
+			ObjectID:       952,
+			ArgumentValues: [][]byte{[]byte("1"), nil},
+			ResultFormat:   pgwire.FormatKindBinary,
+		})
+		require.Equal(t, want, s.out.Bytes())
+	})
+
+	t.Run("Null", func(t *testing.T) {
+		s := newScript(t,
+			&pgwire.MsgFunctionCallResponse{Result: nil},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		c := pgwire.NewConn(s)
+
+		result, err := c.CallFunction(952, nil, false)
+		require.NoError(t, err)
+		require.Nil(t, result)
+	})
+}
+
+func TestConnRole(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ClientSendBackend", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t)
+		c := pgwire.NewConn(s)
+
+		err := c.Send(&pgwire.MsgQuery{Value: "SELECT 1"}, &pgwire.MsgRowDescription{})
+		require.ErrorIs(t, err, pgwire.ErrWrongRole)
+		require.Zero(t, s.out.Len())
+	})
+
+	t.Run("ServerSendFrontend", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t)
+		c := pgwire.NewServerConn(s)
+
+		err := c.Send(&pgwire.MsgQuery{Value: "SELECT 1"})
+		require.ErrorIs(t, err, pgwire.ErrWrongRole)
+		require.Zero(t, s.out.Len())
+
+		_, err = c.SimpleQuery("SELECT 1")
+		require.ErrorIs(t, err, pgwire.ErrWrongRole)
+	})
+
+	t.Run("Server", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t, &pgwire.MsgQuery{Value: "SELECT 1"})
+		c := pgwire.NewServerConn(s)
+		require.Equal(t, pgwire.RoleServer, c.Role())
+
+		m, err := c.Receive()
+		require.NoError(t, err)
+		require.Equal(t, &pgwire.MsgQuery{Value: "SELECT 1"}, m)
+
+		err = c.Send(&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)})
+		require.NoError(t, err)
+		require.Equal(t, appendMessages(t, &pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)}), s.out.Bytes())
+	})
+}
+
+func TestConnBind(t *testing.T) {
+	t.Parallel()
+
+	bind := pgwire.BindText("", "s1", []string{"42"})
+
+	t.Run("Complete", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t, &pgwire.MsgBindComplete{})
+
+		err := pgwire.NewConn(s).Bind(bind)
+		require.NoError(t, err)
+		require.Equal(t, appendMessages(t, bind, &pgwire.MsgFlush{}), s.out.Bytes())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			&pgwire.MsgErrorResponse{
+				Fields: []byte{
+					byte(pgwire.FieldKindSeverity),
+					byte(pgwire.FieldKindCode),
+					byte(pgwire.FieldKindMessage),
+				},
+				Values: []string{"ERROR", "26000", `prepared statement "s1" does not exist`},
+			},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		err := pgwire.NewConn(s).Bind(bind)
+
+		var pgErr *pgwire.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "26", pgErr.SQLStateClass())
+		require.Equal(t, appendMessages(t, bind, &pgwire.MsgFlush{}, &pgwire.MsgSync{}), s.out.Bytes())
+	})
+}
+
+func TestConnNextStatementName(t *testing.T) {
+	t.Parallel()
+
+	c := pgwire.NewConn(newScript(t))
+	identifier := regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+	seen := make(map[string]bool)
+
+	for range 100 {
+		name := c.NextStatementName()
+		require.Regexp(t, identifier, name)
+		require.False(t, seen[name], name)
+		seen[name] = true
+	}
+
+	require.Equal(t, "stmt_101", c.NextStatementName())
+}
+
+func TestConnClose(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Portal", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t, &pgwire.MsgCloseComplete{})
+
+		err := pgwire.NewConn(s).ClosePortal("p1")
+		require.NoError(t, err)
+		require.Equal(t, appendMessages(t,
+			&pgwire.MsgClose{ObjectKind: pgwire.ObjectKindPortal, ObjectName: "p1"},
+			&pgwire.MsgFlush{},
+		), s.out.Bytes())
+	})
+
+	t.Run("Statement", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t, &pgwire.MsgCloseComplete{})
+
+		err := pgwire.NewConn(s).CloseStatement("stmt_1")
+		require.NoError(t, err)
+		require.Equal(t, appendMessages(t,
+			&pgwire.MsgClose{ObjectKind: pgwire.ObjectKindStatement, ObjectName: "stmt_1"},
+			&pgwire.MsgFlush{},
+		), s.out.Bytes())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			&pgwire.MsgErrorResponse{
+				Fields: []byte{
+					byte(pgwire.FieldKindSeverity),
+					byte(pgwire.FieldKindCode),
+					byte(pgwire.FieldKindMessage),
+				},
+				Values: []string{"ERROR", "25P02", "current transaction is aborted"},
+			},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindError)},
+		)
+
+		err := pgwire.NewConn(s).CloseStatement("stmt_1")
+
+		var pgErr *pgwire.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "25", pgErr.SQLStateClass())
+		require.Equal(t, appendMessages(t,
+			&pgwire.MsgClose{ObjectKind: pgwire.ObjectKindStatement, ObjectName: "stmt_1"},
+			&pgwire.MsgFlush{},
+			&pgwire.MsgSync{},
+		), s.out.Bytes())
+	})
+}
+
+type closingScript struct {
+	*script
+	closed bool
+}
+
+func (x *closingScript) Close() error {
+	x.closed = true
+	return nil
+}
+
+func TestConnTerminate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Client", func(t *testing.T) {
+		t.Parallel()
+
+		s := &closingScript{script: newScript(t)}
+
+		err := pgwire.NewConn(s).Close()
+		require.NoError(t, err)
+		require.True(t, s.closed)
+		require.Equal(t, appendMessages(t, &pgwire.MsgTerminate{}), s.out.Bytes())
+	})
+
+	t.Run("Server", func(t *testing.T) {
+		t.Parallel()
+
+		s := &closingScript{script: newScript(t)}
+
+		err := pgwire.NewServerConn(s).Close()
+		require.NoError(t, err)
+		require.True(t, s.closed)
+		require.Empty(t, s.out.Bytes())
+	})
+}
+
+func TestConnRecoversAfterError(t *testing.T) {
+	t.Parallel()
+
+	parse := pgwire.NewParse("s1", "SELECT * FROM missing")
+
+	s := newScript(t,
+		&pgwire.MsgErrorResponse{
+			Fields: []byte{
+				byte(pgwire.FieldKindSeverity),
+				byte(pgwire.FieldKindCode),
+				byte(pgwire.FieldKindMessage),
+			},
+			Values: []string{"ERROR", "42P01", `relation "missing" does not exist`},
+		},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		&pgwire.MsgParseComplete{},
+	)
+
+	c := pgwire.NewConn(s)
+
+	err := c.Parse(parse)
+
+	var pgErr *pgwire.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "42", pgErr.SQLStateClass())
+
+	// The failed Parse was followed by Sync and the reply drained, so the
+	// next operation starts from ReadyForQuery.
+	retry := pgwire.NewParse("s1", "SELECT 1")
+
+	err = c.Parse(retry)
+	require.NoError(t, err)
+	require.Equal(t, appendMessages(t,
+		parse,
+		&pgwire.MsgFlush{},
+		&pgwire.MsgSync{},
+		retry,
+		&pgwire.MsgFlush{},
+	), s.out.Bytes())
+}
+
+func TestConnReceiveFunctionResult(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Result", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			&pgwire.MsgFunctionCallResponse{Result: []byte("42")},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindActive)},
+		)
+
+		result, txStatus, err := pgwire.NewConn(s).ReceiveFunctionResult()
+		require.NoError(t, err)
+		require.Equal(t, []byte("42"), result)
+		require.Equal(t, pgwire.TransactionStatusKindActive, txStatus)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			&pgwire.MsgErrorResponse{
+				Fields: []byte{
+					byte(pgwire.FieldKindSeverity),
+					byte(pgwire.FieldKindCode),
+					byte(pgwire.FieldKindMessage),
+				},
+				Values: []string{"ERROR", "42883", "function 1 does not exist"},
+			},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindError)},
+		)
+
+		result, txStatus, err := pgwire.NewConn(s).ReceiveFunctionResult()
+
+		var pgErr *pgwire.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "42", pgErr.SQLStateClass())
+		require.Nil(t, result)
+		require.Equal(t, pgwire.TransactionStatusKindError, txStatus)
+	})
+}
+
+func TestConnInTransaction(t *testing.T) {
+	t.Parallel()
+
+	complete := func(tag string) *pgwire.MsgCommandComplete {
+		return &pgwire.MsgCommandComplete{Tag: tag}
+	}
+	ready := func(status pgwire.TransactionStatusKind) *pgwire.MsgReadyForQuery {
+		return &pgwire.MsgReadyForQuery{TxStatus: byte(status)}
+	}
+
+	s := newScript(t,
+		complete("SET"), ready(pgwire.TransactionStatusKindIdle),
+		complete("BEGIN"), ready(pgwire.TransactionStatusKindActive),
+		&pgwire.MsgErrorResponse{
+			Fields: []byte{byte(pgwire.FieldKindSeverity), byte(pgwire.FieldKindCode)},
+			Values: []string{"ERROR", "22012"},
+		},
+		ready(pgwire.TransactionStatusKindError),
+		complete("ROLLBACK"), ready(pgwire.TransactionStatusKindIdle),
+	)
+
+	c := pgwire.NewConn(s)
+
+	_, err := c.InTransaction()
+	require.ErrorIs(t, err, pgwire.ErrNoTxStatus)
+
+	steps := []struct {
+		sql  string
+		fail bool
+		want bool
+	}{
+		{sql: "SET x = 1", want: false},
+		{sql: "BEGIN", want: true},
+		{sql: "SELECT 1/0", fail: true, want: true},
+		{sql: "ROLLBACK", want: false},
+	}
+
+	for _, step := range steps {
+		_, err := c.SimpleQuery(step.sql)
+		if step.fail {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+
+		got, err := c.InTransaction()
+		require.NoError(t, err)
+		require.Equal(t, step.want, got, step.sql)
+	}
+}
@@ -0,0 +1,110 @@
+package pgwire_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"gopsql/pgwire"
+	"math/big"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}
+}
+
+func TestConnTLS(t *testing.T) {
+	t.Parallel()
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	server := tls.Server(serverConn, &tls.Config{
+		Certificates: []tls.Certificate{testCertificate(t)},
+	})
+
+	reply := appendMessages(t,
+		&pgwire.MsgCommandComplete{Tag: "SELECT 0"},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	done := make(chan error, 1)
+
+	go func() {
+		defer server.Close()
+
+		r := pgwire.NewMessageReader(server)
+
+		frame, err := r.Next()
+		if err != nil {
+			done <- err
+			return
+		}
+
+		var query pgwire.MsgQuery
+
+		err = query.UnmarshalBinary(frame)
+		if err != nil {
+			done <- err
+			return
+		}
+
+		// Write one byte at a time so every TLS record carries a fragment
+		// of a message.
+		for i := range reply {
+			_, err = server.Write(reply[i : i+1])
+			if err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	client := tls.Client(clientConn, &tls.Config{InsecureSkipVerify: true})
+
+	c := pgwire.NewConn(client)
+	require.NoError(t, c.SetDeadline(time.Now().Add(10*time.Second)))
+
+	result, err := c.SimpleQuery("SELECT 1 WHERE false")
+	require.NoError(t, err)
+	require.Equal(t, "SELECT 0", result.Tag)
+	require.NoError(t, <-done)
+}
+
+func TestConnSetDeadlineUnsupported(t *testing.T) {
+	t.Parallel()
+
+	c := pgwire.NewConn(newScript(t))
+
+	require.ErrorIs(t, c.SetDeadline(time.Now()), os.ErrNoDeadline)
+	require.ErrorIs(t, c.SetReadDeadline(time.Now()), os.ErrNoDeadline)
+	require.ErrorIs(t, c.SetWriteDeadline(time.Now()), os.ErrNoDeadline)
+}
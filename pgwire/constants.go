@@ -1,5 +1,10 @@
 package pgwire
 
+import (
+	"fmt"
+	"gopsql/pgio"
+)
+
 const (
 	cancelHigh        int32 = 1234
 	cancelLow         int32 = 5678
@@ -14,7 +19,9 @@ const (
 
 const (
 	major3             int32 = 3
+	minor0             int32 = 0
 	minor2             int32 = 2
+	ProtocolVersion3_0 int32 = minor0 | major3<<16
 	ProtocolVersion3_2 int32 = minor2 | major3<<16
 )
 
@@ -27,10 +34,37 @@ const (
 
 type MessageKind byte
 
+// MessageKindNone is the Kind of the packets that open a connection, such as
+// StartupMessage and SSLRequest, which have no kind byte.
+const MessageKindNone MessageKind = 0
+
 func (x MessageKind) Is(b byte) bool {
 	return x == MessageKind(b)
 }
 
+// String returns the protocol name of the message kind, such as
+// "ReadyForQuery". A byte shared by a backend and a frontend message names
+// both, backend first, as in "CommandComplete/Close".
+func (x MessageKind) String() string {
+	if x == MessageKindNone {
+		return "None"
+	}
+
+	if name := kindNames[x]; name != "" {
+		return name
+	}
+	return fmt.Sprintf("Unknown(0x%02x)", byte(x))
+}
+
+// ParseKind returns b as a MessageKind, or an error wrapping
+// pgio.ErrUnknownMessageType when b is not the kind of any message.
+func ParseKind(b byte) (MessageKind, error) {
+	if kindNames[b] == "" {
+		return 0, decodeError(b, nil, invalidFormat(pgio.ErrUnknownMessageType))
+	}
+	return MessageKind(b), nil
+}
+
 // Backend messages
 const (
 	MessageKindAuthentication           MessageKind = 'R'
@@ -82,6 +116,61 @@ const (
 	MessageKindCopyDone MessageKind = 'c'
 )
 
+// kindNames is indexed by kind byte; it is empty for unknown kinds. Several
+// kinds are shared by a frontend and a backend message, so it is built from
+// a list rather than a switch.
+var kindNames = func() [256]string {
+	var names [256]string
+
+	for _, kind := range []struct {
+		kind MessageKind
+		name string
+	}{
+		{MessageKindAuthentication, "Authentication"},
+		{MessageKindBackendKeyData, "BackendKeyData"},
+		{MessageKindBindComplete, "BindComplete"},
+		{MessageKindCloseComplete, "CloseComplete"},
+		{MessageKindCommandComplete, "CommandComplete"},
+		{MessageKindCopyInResponse, "CopyInResponse"},
+		{MessageKindCopyOutResponse, "CopyOutResponse"},
+		{MessageKindCopyBothResponse, "CopyBothResponse"},
+		{MessageKindDataRow, "DataRow"},
+		{MessageKindEmptyQueryResponse, "EmptyQueryResponse"},
+		{MessageKindErrorResponse, "ErrorResponse"},
+		{MessageKindFunctionCallResponse, "FunctionCallResponse"},
+		{MessageKindNegotiateProtocolVersion, "NegotiateProtocolVersion"},
+		{MessageKindNoData, "NoData"},
+		{MessageKindNoticeResponse, "NoticeResponse"},
+		{MessageKindNotificationResponse, "NotificationResponse"},
+		{MessageKindParameterDescription, "ParameterDescription"},
+		{MessageKindParameterStatus, "ParameterStatus"},
+		{MessageKindParseComplete, "ParseComplete"},
+		{MessageKindPortalSuspend, "PortalSuspended"},
+		{MessageKindReadyForQuery, "ReadyForQuery"},
+		{MessageKindRowDescription, "RowDescription"},
+		{MessageKindBind, "Bind"},
+		{MessageKindClose, "Close"},
+		{MessageKindCopyFail, "CopyFail"},
+		{MessageKindDescribe, "Describe"},
+		{MessageKindExecute, "Execute"},
+		{MessageKindFlush, "Flush"},
+		{MessageKindFunctionCall, "FunctionCall"},
+		{MessageKindParse, "Parse"},
+		{MessageKindPasswordMessage, "PasswordMessage"},
+		{MessageKindQuery, "Query"},
+		{MessageKindSync, "Sync"},
+		{MessageKindTerminate, "Terminate"},
+		{MessageKindCopyData, "CopyData"},
+		{MessageKindCopyDone, "CopyDone"},
+	} {
+		if names[kind.kind] != "" {
+			names[kind.kind] += "/"
+		}
+		names[kind.kind] += kind.name
+	}
+	return names
+}()
+
 type AuthenticationKind int32
 
 func (x AuthenticationKind) Is(i int32) bool {
@@ -101,6 +190,53 @@ const (
 	AuthenticationKindSASLFinal         AuthenticationKind = 12
 )
 
+// String returns the name of the authentication request, such as "SASL",
+// or "AuthenticationKind(n)" for an unknown one.
+func (x AuthenticationKind) String() string {
+	switch x {
+	case AuthenticationKindOk:
+		return "Ok"
+	case AuthenticationKindKerberosV5:
+		return "KerberosV5"
+	case AuthenticationKindClearTextPassword:
+		return "CleartextPassword"
+	case AuthenticationKindMD5Password:
+		return "MD5Password"
+	case AuthenticationKindGSS:
+		return "GSS"
+	case AuthenticationKindGSSContinue:
+		return "GSSContinue"
+	case AuthenticationKindSSPI:
+		return "SSPI"
+	case AuthenticationKindSASL:
+		return "SASL"
+	case AuthenticationKindSASLContinue:
+		return "SASLContinue"
+	case AuthenticationKindSASLFinal:
+		return "SASLFinal"
+	}
+	return fmt.Sprintf("AuthenticationKind(%d)", int32(x))
+}
+
+// ParseAuthenticationKind returns i as an AuthenticationKind, or an error
+// wrapping pgio.ErrUnknownAuthType when it is not a known request.
+func ParseAuthenticationKind(i int32) (AuthenticationKind, error) {
+	switch x := AuthenticationKind(i); x {
+	case AuthenticationKindOk,
+		AuthenticationKindKerberosV5,
+		AuthenticationKindClearTextPassword,
+		AuthenticationKindMD5Password,
+		AuthenticationKindGSS,
+		AuthenticationKindGSSContinue,
+		AuthenticationKindSSPI,
+		AuthenticationKindSASL,
+		AuthenticationKindSASLContinue,
+		AuthenticationKindSASLFinal:
+		return x, nil
+	}
+	return 0, invalidFormat(pgio.ErrUnknownAuthType)
+}
+
 type FieldKind byte
 
 const (
@@ -124,6 +260,114 @@ const (
 	FieldKindRoutine          FieldKind = 'R'
 )
 
+var fieldNames = []struct {
+	field FieldKind
+	name  string
+}{
+	{FieldKindSeverity, "Severity"},
+	{FieldKindSeverityRaw, "SeverityRaw"},
+	{FieldKindCode, "Code"},
+	{FieldKindMessage, "Message"},
+	{FieldKindDetail, "Detail"},
+	{FieldKindHint, "Hint"},
+	{FieldKindPosition, "Position"},
+	{FieldKindInternalPosition, "InternalPosition"},
+	{FieldKindInternalQuery, "InternalQuery"},
+	{FieldKindWhere, "Where"},
+	{FieldKindSchema, "Schema"},
+	{FieldKindTable, "Table"},
+	{FieldKindColumn, "Column"},
+	{FieldKindDataType, "DataType"},
+	{FieldKindConstraint, "Constraint"},
+	{FieldKindFile, "File"},
+	{FieldKindLine, "Line"},
+	{FieldKindRoutine, "Routine"},
+}
+
+// String returns the name of the field, such as "Severity". Servers may add
+// fields, so an unknown one prints as "Field(0x..)".
+func (x FieldKind) String() string {
+	for _, f := range fieldNames {
+		if f.field == x {
+			return f.name
+		}
+	}
+	return fmt.Sprintf("Field(0x%02x)", byte(x))
+}
+
+// FieldFromName returns the field whose String is name, and false when
+// there is none.
+func FieldFromName(name string) (FieldKind, bool) {
+	for _, f := range fieldNames {
+		if f.name == name {
+			return f.field, true
+		}
+	}
+	return 0, false
+}
+
+// SeverityLevel is the class of a notice or error, from the Severity or
+// SeverityRaw field.
+type SeverityLevel int
+
+const (
+	SeverityLevelUnknown SeverityLevel = iota
+	SeverityLevelDebug
+	SeverityLevelLog
+	SeverityLevelInfo
+	SeverityLevelNotice
+	SeverityLevelWarning
+	SeverityLevelError
+	SeverityLevelFatal
+	SeverityLevelPanic
+)
+
+// ParseSeverityLevel classifies a non-localized severity such as "WARNING"
+// or "DEBUG1". Anything else is SeverityLevelUnknown.
+func ParseSeverityLevel(s string) SeverityLevel {
+	switch s {
+	case "DEBUG", "DEBUG1", "DEBUG2", "DEBUG3", "DEBUG4", "DEBUG5":
+		return SeverityLevelDebug
+	case "LOG":
+		return SeverityLevelLog
+	case "INFO":
+		return SeverityLevelInfo
+	case "NOTICE":
+		return SeverityLevelNotice
+	case "WARNING":
+		return SeverityLevelWarning
+	case "ERROR":
+		return SeverityLevelError
+	case "FATAL":
+		return SeverityLevelFatal
+	case "PANIC":
+		return SeverityLevelPanic
+	}
+	return SeverityLevelUnknown
+}
+
+func (x SeverityLevel) String() string {
+	switch x {
+	case SeverityLevelDebug:
+		return "DEBUG"
+	case SeverityLevelLog:
+		return "LOG"
+	case SeverityLevelInfo:
+		return "INFO"
+	case SeverityLevelNotice:
+		return "NOTICE"
+	case SeverityLevelWarning:
+		return "WARNING"
+	case SeverityLevelError:
+		return "ERROR"
+	case SeverityLevelFatal:
+		return "FATAL"
+	case SeverityLevelPanic:
+		return "PANIC"
+	}
+	return "UNKNOWN"
+}
+
 type ObjectKind byte
 
 const (
@@ -131,6 +375,10 @@ const (
 	ObjectKindPortal    ObjectKind = 'P'
 )
 
+func (x ObjectKind) valid() bool {
+	return x == ObjectKindStatement || x == ObjectKindPortal
+}
+
 type FormatKind byte
 
 const (
@@ -138,6 +386,16 @@ const (
 	FormatKindBinary FormatKind = 1
 )
 
+func (x FormatKind) String() string {
+	switch x {
+	case FormatKindText:
+		return "Text"
+	case FormatKindBinary:
+		return "Binary"
+	}
+	return fmt.Sprintf("FormatKind(%d)", byte(x))
+}
+
 type TransactionStatusKind byte
 
 const (
@@ -146,6 +404,18 @@ const (
 	TransactionStatusKindError  TransactionStatusKind = 'E'
 )
 
+func (x TransactionStatusKind) String() string {
+	switch x {
+	case TransactionStatusKindIdle:
+		return "Idle"
+	case TransactionStatusKindActive:
+		return "InTransaction"
+	case TransactionStatusKindError:
+		return "Failed"
+	}
+	return fmt.Sprintf("TransactionStatusKind(0x%02x)", byte(x))
+}
+
 const (
 	sizeMessageKind   = 1
 	sizeMessageLength = 4
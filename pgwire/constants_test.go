@@ -0,0 +1,153 @@
+package pgwire_test
+
+import (
+	"gopsql/pgio"
+	"gopsql/pgwire"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageKindString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		kind pgwire.MessageKind
+		want string
+	}{
+		{pgwire.MessageKindReadyForQuery, "ReadyForQuery"},
+		{pgwire.MessageKindAuthentication, "Authentication"},
+		{pgwire.MessageKindPortalSuspend, "PortalSuspended"},
+		{pgwire.MessageKindCopyData, "CopyData"},
+		{pgwire.MessageKindCommandComplete, "CommandComplete/Close"},
+		{pgwire.MessageKindSync, "ParameterStatus/Sync"},
+		{pgwire.MessageKindNone, "None"},
+		{'?', "Unknown(0x3f)"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, tt.kind.String())
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	t.Parallel()
+
+	kind, err := pgwire.ParseKind('Z')
+	require.NoError(t, err)
+	require.Equal(t, pgwire.MessageKindReadyForQuery, kind)
+
+	_, err = pgwire.ParseKind('?')
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	require.ErrorIs(t, err, pgio.ErrUnknownMessageType)
+}
+
+func TestFieldKindString(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Severity", pgwire.FieldKindSeverity.String())
+	require.Equal(t, "Code", pgwire.FieldKindCode.String())
+	require.Equal(t, "Message", pgwire.FieldKindMessage.String())
+	require.Equal(t, "Detail", pgwire.FieldKindDetail.String())
+	require.Equal(t, "Hint", pgwire.FieldKindHint.String())
+	require.Equal(t, "Field(0x7a)", pgwire.FieldKind('z').String())
+
+	for _, field := range []pgwire.FieldKind{
+		pgwire.FieldKindSeverity,
+		pgwire.FieldKindSeverityRaw,
+		pgwire.FieldKindCode,
+		pgwire.FieldKindMessage,
+		pgwire.FieldKindDetail,
+		pgwire.FieldKindHint,
+		pgwire.FieldKindPosition,
+		pgwire.FieldKindInternalPosition,
+		pgwire.FieldKindInternalQuery,
+		pgwire.FieldKindWhere,
+		pgwire.FieldKindSchema,
+		pgwire.FieldKindTable,
+		pgwire.FieldKindColumn,
+		pgwire.FieldKindDataType,
+		pgwire.FieldKindConstraint,
+		pgwire.FieldKindFile,
+		pgwire.FieldKindLine,
+		pgwire.FieldKindRoutine,
+	} {
+		got, ok := pgwire.FieldFromName(field.String())
+		require.True(t, ok, field.String())
+		require.Equal(t, field, got)
+	}
+
+	_, ok := pgwire.FieldFromName("Field(0x7a)")
+	require.False(t, ok)
+}
+
+func TestTransactionStatusKindString(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Idle", pgwire.TransactionStatusKindIdle.String())
+	require.Equal(t, "InTransaction", pgwire.TransactionStatusKindActive.String())
+	require.Equal(t, "Failed", pgwire.TransactionStatusKindError.String())
+	require.Equal(t, "TransactionStatusKind(0x3f)", pgwire.TransactionStatusKind('?').String())
+}
+
+func TestFormatKindString(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Text", pgwire.FormatKindText.String())
+	require.Equal(t, "Binary", pgwire.FormatKindBinary.String())
+	require.Equal(t, "FormatKind(2)", pgwire.FormatKind(2).String())
+}
+
+func TestAuthenticationKind(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		kind pgwire.AuthenticationKind
+		want string
+	}{
+		{pgwire.AuthenticationKindOk, "Ok"},
+		{pgwire.AuthenticationKindKerberosV5, "KerberosV5"},
+		{pgwire.AuthenticationKindClearTextPassword, "CleartextPassword"},
+		{pgwire.AuthenticationKindMD5Password, "MD5Password"},
+		{pgwire.AuthenticationKindGSS, "GSS"},
+		{pgwire.AuthenticationKindGSSContinue, "GSSContinue"},
+		{pgwire.AuthenticationKindSSPI, "SSPI"},
+		{pgwire.AuthenticationKindSASL, "SASL"},
+		{pgwire.AuthenticationKindSASLContinue, "SASLContinue"},
+		{pgwire.AuthenticationKindSASLFinal, "SASLFinal"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, tt.kind.String())
+
+		got, err := pgwire.ParseAuthenticationKind(int32(tt.kind))
+		require.NoError(t, err)
+		require.Equal(t, tt.kind, got)
+	}
+
+	require.Equal(t, "AuthenticationKind(6)", pgwire.AuthenticationKind(6).String())
+
+	_, err := pgwire.ParseAuthenticationKind(6)
+	require.ErrorIs(t, err, pgio.ErrUnknownAuthType)
+}
+
+func TestSeverityLevel(t *testing.T) {
+	t.Parallel()
+
+	for _, level := range []pgwire.SeverityLevel{
+		pgwire.SeverityLevelDebug,
+		pgwire.SeverityLevelLog,
+		pgwire.SeverityLevelInfo,
+		pgwire.SeverityLevelNotice,
+		pgwire.SeverityLevelWarning,
+		pgwire.SeverityLevelError,
+		pgwire.SeverityLevelFatal,
+		pgwire.SeverityLevelPanic,
+	} {
+		require.Equal(t, level, pgwire.ParseSeverityLevel(level.String()))
+	}
+
+	require.Equal(t, pgwire.SeverityLevelDebug, pgwire.ParseSeverityLevel("DEBUG5"))
+	require.Equal(t, pgwire.SeverityLevelUnknown, pgwire.ParseSeverityLevel("warning"))
+	require.Equal(t, "UNKNOWN", pgwire.SeverityLevelUnknown.String())
+}
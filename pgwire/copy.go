@@ -0,0 +1,206 @@
+package pgwire
+
+import (
+	"encoding/binary"
+	"fmt"
+	"gopsql/pgio"
+	"io"
+	"math"
+)
+
+// CopyReader streams the data of a COPY TO STDOUT. Read returns the payloads
+// of the CopyData messages as one byte stream and io.EOF once the server has
+// finished the command.
+type CopyReader struct {
+	c    *Conn
+	rc   resultCollector
+	data []byte
+	err  error
+
+	// Response is the CopyOutResponse that started the copy.
+	Response *MsgCopyOutResponse
+}
+
+// CopyOut runs sql, which must be a COPY ... TO STDOUT statement, using the
+// simple query protocol and returns a reader for its data. The reader must
+// be read to io.EOF before the Conn is used again.
+func (c *Conn) CopyOut(sql string) (*CopyReader, error) {
+	err := c.Send(&MsgQuery{Value: sql})
+	if err != nil {
+		return nil, err
+	}
+
+	var rc resultCollector
+
+	for {
+		m, err := c.receiveBackend()
+		if err != nil {
+			return nil, err
+		}
+
+		if m, ok := m.(*MsgCopyOutResponse); ok {
+			return &CopyReader{c: c, Response: m}, nil
+		}
+
+		if rc.add(m) {
+			_, err := rc.result()
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: statement did not start a COPY TO STDOUT", ErrUnexpectedKind)
+		}
+	}
+}
+
+func (x *CopyReader) Read(p []byte) (int, error) {
+	for len(x.data) == 0 {
+		if x.err != nil {
+			return 0, x.err
+		}
+		x.err = x.next()
+	}
+
+	n := copy(p, x.data)
+	x.data = x.data[n:]
+	return n, nil
+}
+
+// next reads the next message of the copy. It returns io.EOF after the
+// closing ReadyForQuery, or the error the server reported.
+func (x *CopyReader) next() error {
+	m, err := x.c.receiveBackend()
+	if err != nil {
+		return err
+	}
+
+	switch m := m.(type) {
+	case *MsgCopyData:
+		x.data = m.Data
+	case *MsgCopyDone:
+	default:
+		if x.rc.add(m) {
+			_, err := x.rc.result()
+			if err != nil {
+				return err
+			}
+			return io.EOF
+		}
+	}
+	return nil
+}
+
+// CopyWriter streams data to a COPY FROM STDIN. Each Write is sent as one
+// CopyData message.
+type CopyWriter struct {
+	c *Conn
+
+	// Response is the CopyInResponse that started the copy.
+	Response *MsgCopyInResponse
+}
+
+// CopyIn runs sql, which must be a COPY ... FROM STDIN statement, using the
+// simple query protocol and returns a writer for its data. The writer must
+// be closed before the Conn is used again.
+func (c *Conn) CopyIn(sql string) (*CopyWriter, error) {
+	err := c.Send(&MsgQuery{Value: sql})
+	if err != nil {
+		return nil, err
+	}
+
+	var rc resultCollector
+
+	for {
+		m, err := c.receiveBackend()
+		if err != nil {
+			return nil, err
+		}
+
+		if m, ok := m.(*MsgCopyInResponse); ok {
+			return &CopyWriter{c: c, Response: m}, nil
+		}
+
+		if rc.add(m) {
+			_, err := rc.result()
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: statement did not start a COPY FROM STDIN", ErrUnexpectedKind)
+		}
+	}
+}
+
+func (x *CopyWriter) Write(p []byte) (int, error) {
+	err := x.c.Send(&MsgCopyData{Data: p})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close ends the copy with CopyDone and waits for the server to finish the
+// command. When the server rejects the data, such as on a constraint
+// violation, its ErrorResponse is returned as a *PgError once it is ready
+// for the next query.
+func (x *CopyWriter) Close() error {
+	err := x.c.Send(&MsgCopyDone{})
+	if err != nil {
+		return err
+	}
+
+	_, err = x.c.collect()
+	return err
+}
+
+// Abort ends the copy with CopyFail, which makes the server fail the command
+// with reason in its error message. It returns that error once the server is
+// ready again.
+func (x *CopyWriter) Abort(reason string) error {
+	err := x.c.Send(&MsgCopyFail{Message: reason})
+	if err != nil {
+		return err
+	}
+
+	_, err = x.c.collect()
+	return err
+}
+
+// CopyDataFrom reads src until io.EOF and writes it to w as CopyData
+// messages carrying at most chunkSize bytes each, without buffering the
+// whole input. A chunkSize of zero or less uses a 64 KiB default. It returns
+// the number of data bytes copied and does not send CopyDone.
+func CopyDataFrom(w io.Writer, src io.Reader, chunkSize int) (int64, error) {
+	const sizeHeader = sizeMessageKind + sizeMessageLength
+
+	if chunkSize <= 0 {
+		chunkSize = copyFlushSize
+	}
+
+	if chunkSize > math.MaxInt32-sizeMessageLength {
+		return 0, invalidFormat(pgio.ErrValueOverflow)
+	}
+
+	frame := make([]byte, sizeHeader+chunkSize)
+	frame[0] = byte(MessageKindCopyData)
+
+	var written int64
+
+	for {
+		n, err := io.ReadFull(src, frame[sizeHeader:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(frame[sizeMessageKind:], uint32(sizeMessageLength+n))
+
+			_, werr := w.Write(frame[:sizeHeader+n])
+			if werr != nil {
+				return written, werr
+			}
+			written += int64(n)
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package pgwire
+
+import (
+	"bytes"
+	"fmt"
+	"gopsql/pgio"
+	"io"
+	"math"
+)
+
+const (
+	sizeCopySignature   = 11
+	sizeCopyFlags       = 4
+	sizeCopyExtension   = 4
+	sizeCopyFieldCount  = 2
+	sizeCopyFieldLength = 4
+	copyFlagsCritical   = 0xffff0000 // bits 0-15 are ignored, bits 16-31 are critical; bit 16 is OIDs
+
+	// copyFlushSize is how much encoded data BinaryCopyWriter buffers before
+	// passing it on as a single write.
+	copyFlushSize = 64 * 1024
+)
+
+var copySignature = []byte("PGCOPY\n\377\r\n\x00")
+
+// BinaryCopyReader decodes the tuples of a COPY ... (FORMAT binary) stream,
+// such as the one returned by CopyOut.
+type BinaryCopyReader struct {
+	r       io.Reader
+	started bool
+	done    bool
+	scratch [sizeCopySignature + sizeCopyFlags + sizeCopyExtension]byte
+}
+
+func NewBinaryCopyReader(r io.Reader) *BinaryCopyReader {
+	return &BinaryCopyReader{r: r}
+}
+
+// Next returns the fields of the next tuple, with nil for NULL. It validates
+// the file header before the first tuple and returns io.EOF once it reads the
+// -1 trailer that ends the stream.
+func (x *BinaryCopyReader) Next() ([][]byte, error) {
+	if x.done {
+		return nil, io.EOF
+	}
+
+	if !x.started {
+		err := x.readHeader()
+		if err != nil {
+			return nil, err
+		}
+		x.started = true
+	}
+
+	b := x.scratch[:sizeCopyFieldCount]
+
+	_, err := io.ReadFull(x.r, b)
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: binary copy stream has no trailer", io.ErrUnexpectedEOF)
+	}
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	count, _, err := pgio.ShiftInt16(b)
+	if err != nil {
+		return nil, invalidFormat(err)
+	}
+
+	if count == -1 {
+		x.done = true
+		return nil, io.EOF
+	}
+
+	if count < 0 {
+		return nil, invalidFormat(pgio.ErrValueUnderflow)
+	}
+
+	fields := make([][]byte, count)
+
+	for i := range fields {
+		fields[i], err = x.readField()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+func (x *BinaryCopyReader) readHeader() error {
+	b := x.scratch[:]
+
+	_, err := io.ReadFull(x.r, b)
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+
+	signature, b, err := pgio.ShiftBytes(b, sizeCopySignature)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
+	if !bytes.Equal(signature, copySignature) {
+		return invalidFormat(ErrCopySignature)
+	}
+
+	flags, b, err := pgio.ShiftInt32(b)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
+	if uint32(flags)&copyFlagsCritical != 0 {
+		return invalidFormat(ErrCopySignature)
+	}
+
+	extension, _, err := pgio.ShiftInt32(b)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
+	if extension < 0 {
+		return invalidFormat(pgio.ErrValueUnderflow)
+	}
+
+	_, err = io.CopyN(io.Discard, x.r, int64(extension))
+	return unexpectedEOF(err)
+}
+
+func (x *BinaryCopyReader) readField() ([]byte, error) {
+	b := x.scratch[:sizeCopyFieldLength]
+
+	_, err := io.ReadFull(x.r, b)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	length, _, err := pgio.ShiftInt32(b)
+	if err != nil {
+		return nil, invalidFormat(err)
+	}
+
+	if length == -1 {
+		return nil, nil
+	}
+
+	if length < 0 {
+		return nil, invalidFormat(pgio.ErrValueUnderflow)
+	}
+
+	// The length comes from the stream, so a corrupt header must not be
+	// able to force an arbitrarily large allocation.
+	if length > MaxMessageLength {
+		return nil, invalidFormat(pgio.ErrValueOverflow)
+	}
+
+	field := make([]byte, length)
+
+	_, err = io.ReadFull(x.r, field)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return field, nil
+}
+
+// BinaryCopyWriter encodes tuples as a COPY ... (FORMAT binary) stream and
+// writes them to w, usually a CopyWriter. Tuples are buffered and written in
+// batches; Close flushes the rest of the stream and closes w.
+type BinaryCopyWriter struct {
+	w       io.WriteCloser
+	buf     []byte
+	started bool
+}
+
+func NewBinaryCopyWriter(w io.WriteCloser) *BinaryCopyWriter {
+	return &BinaryCopyWriter{w: w}
+}
+
+// WriteTuple writes one tuple. A nil field is written as NULL.
+func (x *BinaryCopyWriter) WriteTuple(fields [][]byte) error {
+	if len(fields) > math.MaxInt16 {
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
+
+	for _, field := range fields {
+		if len(field) > math.MaxInt32 {
+			return invalidFormat(pgio.ErrValueOverflow)
+		}
+	}
+
+	x.appendHeader()
+
+	buf := pgio.NewBuffer(x.buf)
+	buf.AppendInt16(int16(len(fields)))
+
+	for _, field := range fields {
+		if field == nil {
+			buf.AppendInt32(-1)
+			continue
+		}
+		buf.AppendInt32(int32(len(field)))
+		buf.AppendByte(field...)
+	}
+	x.buf = buf.Bytes()
+
+	if len(x.buf) >= copyFlushSize {
+		return x.flush()
+	}
+	return nil
+}
+
+// Close writes the end of stream trailer and closes the underlying writer.
+func (x *BinaryCopyWriter) Close() error {
+	x.appendHeader()
+	x.buf = pgio.AppendInt16(x.buf, -1)
+
+	err := x.flush()
+	if err != nil {
+		return err
+	}
+	return x.w.Close()
+}
+
+func (x *BinaryCopyWriter) appendHeader() {
+	if x.started {
+		return
+	}
+
+	buf := pgio.NewBuffer(x.buf)
+	buf.AppendByte(copySignature...)
+	buf.AppendInt32(0) // flags
+	buf.AppendInt32(0) // header extension length
+	x.buf = buf.Bytes()
+	x.started = true
+}
+
+func (x *BinaryCopyWriter) flush() error {
+	_, err := x.w.Write(x.buf)
+	x.buf = x.buf[:0]
+	return err
+}
+
+// unexpectedEOF reports a stream that ends part way through a header or
+// tuple as io.ErrUnexpectedEOF.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
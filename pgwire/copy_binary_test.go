@@ -0,0 +1,300 @@
+package pgwire_test
+
+import (
+	"bytes"
+	"gopsql/pgio"
+	"gopsql/pgwire"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func appendBinaryCopyHeader(b []byte) []byte {
+	buf := pgio.NewBuffer(b)
+	buf.AppendByte([]byte("PGCOPY\n\377\r\n\x00")...)
+	buf.AppendInt32(0) // flags
+	buf.AppendInt32(0) // header extension length
+	return buf.Bytes()
+}
+
+func appendBinaryCopyTuple(b []byte, fields ...[]byte) []byte {
+	buf := pgio.NewBuffer(b)
+	buf.AppendInt16(int16(len(fields)))
+
+	for _, field := range fields {
+		if field == nil {
+			buf.AppendInt32(-1)
+			continue
+		}
+		buf.AppendInt32(int32(len(field)))
+		buf.AppendByte(field...)
+	}
+	return buf.Bytes()
+}
+
+func TestBinaryCopyReader(t *testing.T) {
+	t.Parallel()
+
+	stream := appendBinaryCopyHeader(nil)
+	stream = appendBinaryCopyTuple(stream, []byte{0, 0, 0, 1}, []byte("one"))
+	stream = appendBinaryCopyTuple(stream, []byte{0, 0, 0, 2}, nil)
+	stream = pgio.AppendInt16(stream, -1)
+
+	s := newScript(t,
+		&pgwire.MsgCopyOutResponse{Format: 1, Columns: []int16{1, 1}},
+		&pgwire.MsgCopyData{Data: stream[:7]},
+		&pgwire.MsgCopyData{Data: stream[7:]},
+		&pgwire.MsgCopyDone{},
+		&pgwire.MsgCommandComplete{Tag: "COPY 2"},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	cr, err := c.CopyOut("COPY t TO STDOUT (FORMAT binary)")
+	require.NoError(t, err)
+	require.Equal(t, int8(1), cr.Response.Format)
+
+	r := pgwire.NewBinaryCopyReader(cr)
+
+	tuple, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{0, 0, 0, 1}, []byte("one")}, tuple)
+
+	tuple, err = r.Next()
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{0, 0, 0, 2}, nil}, tuple)
+
+	_, err = r.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestBinaryCopyReaderSignature(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		stream := appendBinaryCopyHeader(nil)
+		stream[0] = 'X'
+
+		_, err := pgwire.NewBinaryCopyReader(bytes.NewReader(stream)).Next()
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgwire.ErrCopySignature)
+	})
+
+	t.Run("Short", func(t *testing.T) {
+		t.Parallel()
+
+		stream := appendBinaryCopyHeader(nil)
+
+		_, err := pgwire.NewBinaryCopyReader(bytes.NewReader(stream[:9])).Next()
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("Extension", func(t *testing.T) {
+		t.Parallel()
+
+		buf := pgio.NewBuffer(nil)
+		buf.AppendByte([]byte("PGCOPY\n\377\r\n\x00")...)
+		buf.AppendInt32(0)
+		buf.AppendInt32(3)
+		buf.AppendByte(1, 2, 3)
+
+		stream := appendBinaryCopyTuple(buf.Bytes(), []byte("a"))
+		stream = pgio.AppendInt16(stream, -1)
+
+		tuple, err := pgwire.NewBinaryCopyReader(bytes.NewReader(stream)).Next()
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("a")}, tuple)
+	})
+}
+
+func TestBinaryCopyReaderFlags(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		flags    uint32
+		rejected bool
+	}{
+		"IgnoredLowBit":  {flags: 0x00000001},
+		"OIDs":           {flags: 0x00010000, rejected: true},
+		"UnknownHighBit": {flags: 0x80000000, rejected: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := pgio.NewBuffer(nil)
+			buf.AppendByte([]byte("PGCOPY\n\377\r\n\x00")...)
+			buf.AppendInt32(int32(tt.flags))
+			buf.AppendInt32(0)
+
+			stream := appendBinaryCopyTuple(buf.Bytes(), []byte("a"))
+			stream = pgio.AppendInt16(stream, -1)
+
+			tuple, err := pgwire.NewBinaryCopyReader(bytes.NewReader(stream)).Next()
+			if tt.rejected {
+				require.ErrorIs(t, err, pgwire.ErrCopySignature)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, [][]byte{[]byte("a")}, tuple)
+		})
+	}
+}
+
+func TestConnCopyOutError(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgErrorResponse{
+			Fields: []byte{
+				byte(pgwire.FieldKindSeverity),
+				byte(pgwire.FieldKindCode),
+				byte(pgwire.FieldKindMessage),
+			},
+			Values: []string{"ERROR", "42P01", `relation "t" does not exist`},
+		},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	_, err := pgwire.NewConn(s).CopyOut("COPY t TO STDOUT")
+
+	var pgErr *pgwire.PgError
+	require.ErrorAs(t, err, &pgErr)
+}
+
+func TestBinaryCopyWriter(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgCopyInResponse{Format: 1, Columns: []int16{1, 1, 1}},
+		&pgwire.MsgCommandComplete{Tag: "COPY 3"},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	cw, err := c.CopyIn("COPY t FROM STDIN (FORMAT binary)")
+	require.NoError(t, err)
+
+	tuples := [][][]byte{
+		{[]byte{0, 0, 0, 1}, []byte("one"), nil},
+		{[]byte{0, 0, 0, 2}, nil, []byte{}},
+		{[]byte{0, 0, 0, 3}, []byte("three"), []byte("3")},
+	}
+
+	w := pgwire.NewBinaryCopyWriter(cw)
+
+	for _, tuple := range tuples {
+		err = w.WriteTuple(tuple)
+		require.NoError(t, err)
+	}
+
+	err = w.Close()
+	require.NoError(t, err)
+
+	mr := pgwire.NewMessageReader(&s.out)
+
+	m, err := mr.ReadFrontend()
+	require.NoError(t, err)
+	require.Equal(t, &pgwire.MsgQuery{Value: "COPY t FROM STDIN (FORMAT binary)"}, m)
+
+	var stream []byte
+
+	for {
+		m, err = mr.ReadFrontend()
+		require.NoError(t, err)
+
+		data, ok := m.(*pgwire.MsgCopyData)
+		if !ok {
+			require.Equal(t, &pgwire.MsgCopyDone{}, m)
+			break
+		}
+		stream = append(stream, data.Data...)
+	}
+
+	r := pgwire.NewBinaryCopyReader(bytes.NewReader(stream))
+
+	for _, want := range tuples {
+		got, err := r.Next()
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	_, err = r.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestBinaryCopyReaderTrailer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Present", func(t *testing.T) {
+		t.Parallel()
+
+		stream := appendBinaryCopyHeader(nil)
+		stream = appendBinaryCopyTuple(stream, []byte("a"))
+		stream = pgio.AppendInt16(stream, -1)
+
+		r := pgwire.NewBinaryCopyReader(bytes.NewReader(stream))
+
+		_, err := r.Next()
+		require.NoError(t, err)
+
+		_, err = r.Next()
+		require.Equal(t, io.EOF, err)
+
+		_, err = r.Next()
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		stream := appendBinaryCopyHeader(nil)
+		stream = pgio.AppendInt16(stream, -1)
+
+		_, err := pgwire.NewBinaryCopyReader(bytes.NewReader(stream)).Next()
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		t.Parallel()
+
+		stream := appendBinaryCopyHeader(nil)
+		stream = appendBinaryCopyTuple(stream, []byte("a"))
+
+		r := pgwire.NewBinaryCopyReader(bytes.NewReader(stream))
+
+		_, err := r.Next()
+		require.NoError(t, err)
+
+		_, err = r.Next()
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		require.ErrorContains(t, err, "no trailer")
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		t.Parallel()
+
+		stream := appendBinaryCopyHeader(nil)
+		stream = appendBinaryCopyTuple(stream, []byte("abc"))
+
+		_, err := pgwire.NewBinaryCopyReader(bytes.NewReader(stream[:len(stream)-1])).Next()
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+	t.Run("FieldTooLong", func(t *testing.T) {
+		t.Parallel()
+
+		stream := appendBinaryCopyHeader(nil)
+		stream = pgio.AppendInt16(stream, 1)
+		stream = pgio.AppendInt32(stream, pgwire.MaxMessageLength+1)
+
+		_, err := pgwire.NewBinaryCopyReader(bytes.NewReader(stream)).Next()
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueOverflow)
+	})
+}
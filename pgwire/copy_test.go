@@ -0,0 +1,149 @@
+package pgwire_test
+
+import (
+	"bytes"
+	"gopsql/pgwire"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyDataFrom(t *testing.T) {
+	t.Parallel()
+
+	src := bytes.Repeat([]byte("0123456789"), 25)
+
+	var out bytes.Buffer
+
+	// OneByteReader makes every read short, so chunks must still be filled
+	// across reads.
+	n, err := pgwire.CopyDataFrom(&out, iotest.OneByteReader(bytes.NewReader(src)), 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(src)), n)
+
+	r := pgwire.NewMessageReader(&out)
+
+	var sizes []int
+	var got []byte
+
+	for {
+		m, err := r.ReadFrontend()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		data, ok := m.(*pgwire.MsgCopyData)
+		require.True(t, ok)
+
+		sizes = append(sizes, len(data.Data))
+		got = append(got, data.Data...)
+	}
+
+	require.Equal(t, []int{100, 100, 50}, sizes)
+	require.Equal(t, src, got)
+}
+
+func TestCopyDataFromEmpty(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	n, err := pgwire.CopyDataFrom(&out, bytes.NewReader(nil), 0)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.Zero(t, out.Len())
+}
+
+func TestCopyDataFromReadError(t *testing.T) {
+	t.Parallel()
+
+	src := io.MultiReader(bytes.NewReader([]byte("abc")), iotest.ErrReader(io.ErrClosedPipe))
+
+	var out bytes.Buffer
+
+	n, err := pgwire.CopyDataFrom(&out, src, 2)
+	require.ErrorIs(t, err, io.ErrClosedPipe)
+	require.Equal(t, int64(3), n)
+	require.Equal(t, appendMessages(t,
+		&pgwire.MsgCopyData{Data: []byte("ab")},
+		&pgwire.MsgCopyData{Data: []byte("c")},
+	), out.Bytes())
+}
+
+func TestCopyWriterAbort(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgCopyInResponse{Columns: []int16{0}},
+		&pgwire.MsgErrorResponse{
+			Fields: []byte{'S', 'C', 'M'},
+			Values: []string{"ERROR", "57014", "COPY from stdin failed: bad input"},
+		},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	cw, err := c.CopyIn("COPY t FROM STDIN")
+	require.NoError(t, err)
+
+	_, err = cw.Write([]byte("1\n"))
+	require.NoError(t, err)
+
+	err = cw.Abort("bad input")
+
+	var pgErr *pgwire.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.ErrorContains(t, err, "bad input")
+
+	require.Equal(t, appendMessages(t,
+		&pgwire.MsgQuery{Value: "COPY t FROM STDIN"},
+		&pgwire.MsgCopyData{Data: []byte("1\n")},
+		&pgwire.MsgCopyFail{Message: "bad input"},
+	), s.out.Bytes())
+}
+
+func TestCopyWriterCloseError(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgCopyInResponse{Columns: []int16{0}},
+		&pgwire.MsgErrorResponse{
+			Fields: []byte{'S', 'C', 'M'},
+			Values: []string{"ERROR", "23505", `duplicate key value violates unique constraint "t_pkey"`},
+		},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		&pgwire.MsgCommandComplete{Tag: "SELECT 0"},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	cw, err := c.CopyIn("COPY t FROM STDIN")
+	require.NoError(t, err)
+
+	_, err = cw.Write([]byte("1\n1\n"))
+	require.NoError(t, err)
+
+	err = cw.Close()
+
+	var pgErr *pgwire.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "23", pgErr.SQLStateClass())
+	require.ErrorContains(t, err, "t_pkey")
+
+	// The ReadyForQuery after the error was consumed, so the Conn is usable.
+	result, err := c.SimpleQuery("SELECT 1 WHERE false")
+	require.NoError(t, err)
+	require.Equal(t, "SELECT 0", result.Tag)
+
+	require.Equal(t, appendMessages(t,
+		&pgwire.MsgQuery{Value: "COPY t FROM STDIN"},
+		&pgwire.MsgCopyData{Data: []byte("1\n1\n")},
+		&pgwire.MsgCopyDone{},
+		&pgwire.MsgQuery{Value: "SELECT 1 WHERE false"},
+	), s.out.Bytes())
+}
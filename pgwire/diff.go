@@ -0,0 +1,151 @@
+package pgwire
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Equal reports whether a and b, typically two decoded messages, are equal
+// field by field. A nil byte slice is not equal to an empty one, since the
+// protocol distinguishes NULL from an empty value.
+func Equal(a, b any) bool {
+	return Diff(a, b) == ""
+}
+
+// Diff returns a readable description of how a and b differ, one line per
+// differing field such as `Columns[1]: "a" != "b"`, or "" when they are
+// equal.
+func Diff(a, b any) string {
+	var d differ
+	d.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+	return strings.Join(d.lines, "\n")
+}
+
+type differ struct {
+	lines []string
+}
+
+func (d *differ) add(path, format string, args ...any) {
+	if path == "" {
+		path = "value"
+	}
+	d.lines = append(d.lines, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (d *differ) diff(path string, a, b reflect.Value) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.add(path, "%s != %s", formatValue(a), formatValue(b))
+		}
+		return
+	}
+
+	if a.Type() != b.Type() {
+		d.add(path, "type %s != %s", a.Type(), b.Type())
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, "%s != %s", formatValue(a), formatValue(b))
+			}
+			return
+		}
+		d.diff(path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := range a.NumField() {
+			name := a.Type().Field(i).Name
+			if path != "" {
+				name = path + "." + name
+			}
+			d.diff(name, a.Field(i), b.Field(i))
+		}
+	case reflect.Slice:
+		if a.IsNil() != b.IsNil() {
+			d.add(path, "%s != %s", formatValue(a), formatValue(b))
+			return
+		}
+
+		if a.Type().Elem().Kind() == reflect.Uint8 {
+			if !bytes.Equal(a.Bytes(), b.Bytes()) {
+				d.add(path, "%s != %s", formatValue(a), formatValue(b))
+			}
+			return
+		}
+		d.diffElems(path, a, b)
+	case reflect.Array:
+		d.diffElems(path, a, b)
+	case reflect.Map:
+		d.diffMaps(path, a, b)
+	default:
+		x, y := formatValue(a), formatValue(b)
+		if x != y {
+			d.add(path, "%s != %s", x, y)
+		}
+	}
+}
+
+func (d *differ) diffElems(path string, a, b reflect.Value) {
+	if a.Len() != b.Len() {
+		d.add(path, "len %d != %d", a.Len(), b.Len())
+	}
+
+	for i := range min(a.Len(), b.Len()) {
+		d.diff(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i))
+	}
+}
+
+func (d *differ) diffMaps(path string, a, b reflect.Value) {
+	if a.IsNil() != b.IsNil() {
+		d.add(path, "%s != %s", formatValue(a), formatValue(b))
+		return
+	}
+
+	keys := make(map[string]reflect.Value)
+	for _, k := range a.MapKeys() {
+		keys[fmt.Sprintf("%#v", k)] = k
+	}
+	for _, k := range b.MapKeys() {
+		keys[fmt.Sprintf("%#v", k)] = k
+	}
+
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		k := keys[name]
+		d.diff(fmt.Sprintf("%s[%s]", path, name), a.MapIndex(k), b.MapIndex(k))
+	}
+}
+
+// formatValue prints v, spelling out nil and quoting byte slices and
+// strings so that a NULL and an empty value read differently.
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "missing"
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return "nil"
+		}
+	}
+
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return fmt.Sprintf("%q", v.Bytes())
+	}
+
+	if v.Kind() == reflect.String {
+		return fmt.Sprintf("%q", v.String())
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,68 @@
+package pgwire_test
+
+import (
+	"gopsql/pgwire"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	row := func(columns ...[]byte) *pgwire.MsgDataRow {
+		return &pgwire.MsgDataRow{Columns: columns}
+	}
+
+	t.Run("Equal", func(t *testing.T) {
+		t.Parallel()
+
+		a := row([]byte("1"), nil, []byte{})
+		b := row([]byte("1"), nil, []byte{})
+
+		require.True(t, pgwire.Equal(a, b))
+		require.Empty(t, pgwire.Diff(a, b))
+	})
+
+	t.Run("Column", func(t *testing.T) {
+		t.Parallel()
+
+		a := row([]byte("1"), []byte("a"), []byte("3"))
+		b := row([]byte("1"), []byte("b"), []byte("3"))
+
+		require.False(t, pgwire.Equal(a, b))
+		require.Equal(t, `Columns[1]: "a" != "b"`, pgwire.Diff(a, b))
+	})
+
+	t.Run("NullAndEmpty", func(t *testing.T) {
+		t.Parallel()
+
+		a := row(nil)
+		b := row([]byte{})
+
+		require.False(t, pgwire.Equal(a, b))
+		require.Equal(t, `Columns[0]: nil != ""`, pgwire.Diff(a, b))
+	})
+
+	t.Run("ColumnCount", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t, "Columns: len 1 != 2", pgwire.Diff(row([]byte("1")), row([]byte("1"), nil)))
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		t.Parallel()
+
+		diff := pgwire.Diff(row(), &pgwire.MsgSync{})
+		require.Equal(t, "value: type *pgwire.MsgDataRow != *pgwire.MsgSync", diff)
+	})
+
+	t.Run("Fields", func(t *testing.T) {
+		t.Parallel()
+
+		a := &pgwire.MsgParameterStatus{Name: "TimeZone", Value: "UTC"}
+		b := &pgwire.MsgParameterStatus{Name: "TimeZone", Value: "Europe/Paris"}
+
+		require.Equal(t, `Value: "UTC" != "Europe/Paris"`, pgwire.Diff(a, b))
+	})
+}
@@ -3,6 +3,31 @@ package pgwire
 import "errors"
 
 var (
-	ErrInvalidFormat  = errors.New("invalid format")
-	ErrUnexpectedKind = errors.New("unexpected kind")
+	ErrInvalidFormat   = errors.New("invalid format")
+	ErrUnexpectedKind  = errors.New("unexpected kind")
+	ErrWrongRole       = errors.New("message not valid for connection role")
+	ErrCopySignature   = errors.New("invalid binary copy header")
+	ErrBudgetExceeded  = errors.New("message reader byte budget exceeded")
+	ErrNoTxStatus      = errors.New("no ReadyForQuery received")
+	ErrUnsupportedType = errors.New("unsupported data type")
 )
+
+// PgError is an ErrorResponse received from the server, surfaced as a Go
+// error.
+type PgError struct {
+	MsgErrorResponse
+}
+
+func newPgError(m *MsgErrorResponse) *PgError {
+	return &PgError{MsgErrorResponse: *m}
+}
+
+func (x *PgError) Error() string {
+	return x.MsgErrorResponse.Error()
+}
+
+// Unwrap returns the ErrorResponse, so errors.As can extract a
+// *MsgErrorResponse as well as a *PgError.
+func (x *PgError) Unwrap() error {
+	return &x.MsgErrorResponse
+}
@@ -0,0 +1,74 @@
+package pgwire
+
+import (
+	"fmt"
+	"gopsql/pgio"
+)
+
+// Frame is one typed message as it appears on the wire: the kind byte, the
+// length and the body. It lets a proxy inspect the kind of a message and
+// forward it without decoding it. Kind and Body expect a well-formed frame,
+// as returned by MessageReader.Next, NewFrame or ParseFrame.
+type Frame []byte
+
+// NewFrame builds the frame for a message of kind with body. It fails when
+// the frame would be longer than MaxMessageLength.
+func NewFrame(kind MessageKind, body []byte) (Frame, error) {
+	if len(body) > MaxMessageLength-sizeMessageLength {
+		return nil, invalidFormat(pgio.ErrValueOverflow)
+	}
+
+	buf := pgio.NewBuffer(nil)
+	buf.Grow(sizeMessageKind + sizeMessageLength + len(body))
+	buf.AppendByte(byte(kind))
+	buf.AppendInt32(int32(sizeMessageLength + len(body)))
+	buf.AppendByte(body...)
+	return Frame(buf.Bytes()), nil
+}
+
+// ParseFrame checks that b holds exactly one frame, with a nonzero kind and
+// a length that matches the bytes that follow it, and returns it as a Frame
+// without copying.
+func ParseFrame(b []byte) (Frame, error) {
+	if len(b) < sizeMessageKind+sizeMessageLength {
+		return nil, invalidFormat(pgio.ErrValueUnderflow)
+	}
+
+	if b[0] == 0 {
+		return nil, fmt.Errorf("%w: zero message kind", invalidFormat(pgio.ErrInvalidValue))
+	}
+
+	length, _, err := pgio.ShiftInt32(b[sizeMessageKind:])
+	if err != nil {
+		return nil, invalidFormat(err)
+	}
+
+	err = validateLength(length)
+	if err != nil {
+		return nil, err
+	}
+
+	if int(length) != len(b)-sizeMessageKind {
+		return nil, fmt.Errorf("%w: frame length %d does not match %d bytes",
+			ErrInvalidFormat, length, len(b)-sizeMessageKind)
+	}
+	return Frame(b), nil
+}
+
+// Kind returns the kind byte, or MessageKindNone for an empty frame.
+func (x Frame) Kind() MessageKind {
+	if len(x) < sizeMessageKind {
+		return MessageKindNone
+	}
+	return MessageKind(x[0])
+}
+
+// Body returns the bytes after the length, or nil for a frame too short to
+// have a length. It shares memory with the frame and must be treated as
+// read-only.
+func (x Frame) Body() []byte {
+	if len(x) < sizeMessageKind+sizeMessageLength {
+		return nil
+	}
+	return x[sizeMessageKind+sizeMessageLength:]
+}
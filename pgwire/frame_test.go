@@ -0,0 +1,85 @@
+package pgwire_test
+
+import (
+	"bytes"
+	"gopsql/pgio"
+	"gopsql/pgwire"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrame(t *testing.T) {
+	t.Parallel()
+
+	b := appendMessages(t,
+		&pgwire.MsgQuery{Value: "SELECT 1"},
+		&pgwire.MsgSync{},
+	)
+
+	r := pgwire.NewMessageReader(bytes.NewReader(b))
+
+	frame, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, pgwire.MessageKindQuery, frame.Kind())
+	require.Equal(t, []byte("SELECT 1\x00"), frame.Body())
+
+	built, err := pgwire.NewFrame(pgwire.MessageKindQuery, frame.Body())
+	require.NoError(t, err)
+	require.Equal(t, frame, built)
+
+	var query pgwire.MsgQuery
+
+	err = query.UnmarshalBinary(frame)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT 1", query.Value)
+
+	frame, err = r.Next()
+	require.NoError(t, err)
+	require.Equal(t, pgwire.MessageKindSync, frame.Kind())
+	require.Empty(t, frame.Body())
+
+	built, err = pgwire.NewFrame(pgwire.MessageKindSync, nil)
+	require.NoError(t, err)
+	require.Equal(t, appendMessages(t, &pgwire.MsgSync{}), []byte(built))
+}
+
+func TestParseFrame(t *testing.T) {
+	t.Parallel()
+
+	b := appendMessages(t, &pgwire.MsgQuery{Value: "SELECT 1"})
+
+	frame, err := pgwire.ParseFrame(b)
+	require.NoError(t, err)
+	require.Equal(t, pgwire.MessageKindQuery, frame.Kind())
+	require.Equal(t, []byte("SELECT 1\x00"), frame.Body())
+
+	for name, b := range map[string][]byte{
+		"Empty":     nil,
+		"Short":     {'Q', 0, 0, 0},
+		"ZeroKind":  {0, 0, 0, 0, 4},
+		"TooShort":  {'Q', 0, 0, 0, 3},
+		"Truncated": {'Q', 0, 0, 0, 6, 'a'},
+		"Trailing":  {'Q', 0, 0, 0, 4, 'a'},
+	} {
+		_, err := pgwire.ParseFrame(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat, name)
+	}
+}
+
+func TestFrameShort(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, pgwire.MessageKindNone, pgwire.Frame(nil).Kind())
+	require.Nil(t, pgwire.Frame(nil).Body())
+	require.Equal(t, pgwire.MessageKindQuery, pgwire.Frame{'Q', 0}.Kind())
+	require.Nil(t, pgwire.Frame{'Q', 0}.Body())
+}
+
+func TestNewFrameTooLong(t *testing.T) {
+	t.Parallel()
+
+	_, err := pgwire.NewFrame(pgwire.MessageKindCopyData, make([]byte, pgwire.MaxMessageLength-3))
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	require.ErrorIs(t, err, pgio.ErrValueOverflow)
+}
@@ -2,10 +2,17 @@ package pgwire
 
 import "encoding"
 
+// Message is a protocol message. A decoded message may share memory with the
+// bytes passed to UnmarshalBinary; strings in particular are not copied. The
+// input must not be modified or reused while the message is in use. Messages
+// with a Clone method can be detached from their input with it.
 type Message interface {
 	encoding.BinaryAppender
 	encoding.BinaryUnmarshaler
 
+	// Kind returns the kind byte that starts the message's frame.
+	Kind() MessageKind
+
 	message()
 }
 
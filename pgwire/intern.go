@@ -0,0 +1,43 @@
+package pgwire
+
+import "strings"
+
+// SetInternLimit makes ReadBackend share one copy of each repeated
+// RowDescription column name and ParameterStatus name, keeping at most n
+// distinct strings. Once the cache is full, new names are returned as they
+// are. Zero disables interning and drops the cache.
+func (x *MessageReader) SetInternLimit(n int) {
+	x.internLimit = n
+	x.interned = nil
+
+	if n > 0 {
+		x.interned = make(map[string]string)
+	}
+}
+
+func (x *MessageReader) intern(m Backend) {
+	switch m := m.(type) {
+	case *MsgParameterStatus:
+		m.Name = x.internString(m.Name)
+	case *MsgRowDescription:
+		for i, name := range m.Names {
+			m.Names[i] = x.internString(name)
+		}
+	}
+}
+
+func (x *MessageReader) internString(s string) string {
+	if interned, ok := x.interned[s]; ok {
+		return interned
+	}
+
+	if len(x.interned) >= x.internLimit {
+		return s
+	}
+
+	// Decoded strings reference the frame, so keep a copy that does not pin
+	// it in memory.
+	s = strings.Clone(s)
+	x.interned[s] = s
+	return s
+}
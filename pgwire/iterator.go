@@ -0,0 +1,109 @@
+package pgwire
+
+// ResultIterator streams the rows of a query one DataRow at a time. Each
+// call to Next reads only as far as the next row, so a consumer slower than
+// the network applies back pressure instead of having the whole result
+// buffered in memory. Next must be called until it returns false, or Close
+// called, before the Conn is used again.
+type ResultIterator struct {
+	c    *Conn
+	row  [][]byte
+	tag  string
+	err  error
+	done bool
+
+	// Description is nil for statements that return no rows.
+	Description *MsgRowDescription
+}
+
+// Query runs sql with params as Exec does, but returns an iterator over the
+// rows instead of collecting them. An error reported before the first row
+// is returned once the server is ready again.
+func (c *Conn) Query(sql string, params [][]byte) (*ResultIterator, error) {
+	err := c.Send(
+		&MsgParse{Query: sql},
+		&MsgBind{ParameterData: params},
+		&MsgDescribe{ObjectKind: ObjectKindPortal},
+		&MsgExecute{},
+		&MsgSync{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &ResultIterator{c: c}
+
+	for {
+		m, err := c.receiveBackend()
+		if err != nil {
+			return nil, err
+		}
+
+		switch m := m.(type) {
+		case *MsgRowDescription:
+			x.Description = m
+			return x, nil
+		case *MsgNoData:
+			return x, nil
+		case *MsgErrorResponse:
+			x.err = newPgError(m)
+			return nil, x.Close()
+		case *MsgReadyForQuery:
+			x.done = true
+			return x, nil
+		}
+	}
+}
+
+// Next reads up to the next row and reports whether there is one. It
+// returns false at the end of the result or on error; see Err.
+func (x *ResultIterator) Next() bool {
+	x.row = nil
+
+	for !x.done {
+		m, err := x.c.receiveBackend()
+		if err != nil {
+			x.err, x.done = err, true
+			break
+		}
+
+		switch m := m.(type) {
+		case *MsgDataRow:
+			x.row = m.Columns
+			return true
+		case *MsgCommandComplete:
+			x.tag = m.Tag
+		case *MsgErrorResponse:
+			if x.err == nil {
+				x.err = newPgError(m)
+			}
+		case *MsgReadyForQuery:
+			x.done = true
+		}
+	}
+	return false
+}
+
+// Row returns the columns of the current row. A nil column is NULL. The
+// row is only valid until the next call to Next, which may reuse its
+// buffers; to keep it, copy it, for example with MsgDataRow.Clone.
+func (x *ResultIterator) Row() [][]byte {
+	return x.row
+}
+
+// Tag returns the CommandComplete tag once Next has returned false.
+func (x *ResultIterator) Tag() string {
+	return x.tag
+}
+
+// Err returns the error that ended the iteration, if any.
+func (x *ResultIterator) Err() error {
+	return x.err
+}
+
+// Close discards the remaining rows and returns Err.
+func (x *ResultIterator) Close() error {
+	for x.Next() {
+	}
+	return x.err
+}
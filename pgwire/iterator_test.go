@@ -0,0 +1,142 @@
+package pgwire_test
+
+import (
+	"bytes"
+	"gopsql/pgwire"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// frameConn delivers one frame per Read and counts the bytes consumed.
+type frameConn struct {
+	frames   [][]byte
+	consumed int
+	out      bytes.Buffer
+}
+
+func (x *frameConn) Read(p []byte) (int, error) {
+	if len(x.frames) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, x.frames[0])
+	x.frames[0] = x.frames[0][n:]
+	if len(x.frames[0]) == 0 {
+		x.frames = x.frames[1:]
+	}
+	x.consumed += n
+	return n, nil
+}
+
+func (x *frameConn) Write(p []byte) (int, error) {
+	return x.out.Write(p)
+}
+
+func TestConnQuery(t *testing.T) {
+	t.Parallel()
+
+	desc := pgwire.NewRowDescription([]pgwire.ColumnDescription{{Name: "n", DataType: 23, Size: 4, Modifier: -1}})
+
+	msgs := []pgwire.Message{
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgBindComplete{},
+		desc,
+		&pgwire.MsgDataRow{Columns: [][]byte{[]byte("1")}},
+		&pgwire.MsgDataRow{Columns: [][]byte{nil}},
+		&pgwire.MsgDataRow{Columns: [][]byte{[]byte("3")}},
+		&pgwire.MsgCommandComplete{Tag: "SELECT 3"},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	}
+
+	conn := &frameConn{}
+	size := make([]int, len(msgs))
+
+	for i, m := range msgs {
+		frame := appendMessages(t, m)
+		conn.frames = append(conn.frames, frame)
+		size[i] = len(frame)
+	}
+
+	// sum returns the size of the first n frames.
+	sum := func(n int) int {
+		total := 0
+		for _, s := range size[:n] {
+			total += s
+		}
+		return total
+	}
+
+	c := pgwire.NewConn(conn)
+
+	it, err := c.Query("SELECT n FROM t", nil)
+	require.NoError(t, err)
+	require.Equal(t, desc, it.Description)
+
+	// Nothing past the RowDescription has been read yet.
+	require.Equal(t, sum(3), conn.consumed)
+
+	want := [][][]byte{{[]byte("1")}, {nil}, {[]byte("3")}}
+
+	for i, row := range want {
+		require.True(t, it.Next())
+		require.Equal(t, row, it.Row())
+		require.Equal(t, sum(4+i), conn.consumed)
+	}
+
+	require.False(t, it.Next())
+	require.NoError(t, it.Err())
+	require.Equal(t, "SELECT 3", it.Tag())
+	require.Equal(t, sum(len(msgs)), conn.consumed)
+}
+
+func TestConnQueryError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("BeforeRows", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			&pgwire.MsgErrorResponse{Fields: []byte("SCM"), Values: []string{"ERROR", "42P01", `relation "t" does not exist`}},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+			&pgwire.MsgParseComplete{},
+		)
+
+		c := pgwire.NewConn(s)
+
+		_, err := c.Query("SELECT n FROM t", nil)
+
+		var pgErr *pgwire.PgError
+		require.ErrorAs(t, err, &pgErr)
+
+		// The ReadyForQuery was consumed.
+		m, err := c.Receive()
+		require.NoError(t, err)
+		require.IsType(t, &pgwire.MsgParseComplete{}, m)
+	})
+
+	t.Run("DuringRows", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			&pgwire.MsgParseComplete{},
+			&pgwire.MsgBindComplete{},
+			pgwire.NewRowDescription([]pgwire.ColumnDescription{{Name: "n"}}),
+			&pgwire.MsgDataRow{Columns: [][]byte{[]byte("1")}},
+			&pgwire.MsgErrorResponse{Fields: []byte("SCM"), Values: []string{"ERROR", "22012", "division by zero"}},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		it, err := pgwire.NewConn(s).Query("SELECT 1/n FROM t", nil)
+		require.NoError(t, err)
+
+		require.True(t, it.Next())
+		require.False(t, it.Next())
+
+		var pgErr *pgwire.PgError
+		require.ErrorAs(t, it.Err(), &pgErr)
+		require.Equal(t, "22", pgErr.SQLStateClass())
+		require.ErrorIs(t, it.Close(), it.Err())
+	})
+}
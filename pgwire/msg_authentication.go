@@ -1,10 +1,41 @@
 package pgwire
 
 import (
+	"crypto/rand"
 	"gopsql/pgio"
 	"math"
 )
 
+// ParseAuthentication decodes the body of an Authentication message, the
+// bytes following its kind and length, into the type named by its leading
+// authentication kind.
+func ParseAuthentication(body []byte) (Backend, error) {
+	length := sizeMessageLength + len(body)
+
+	if length > math.MaxInt32 {
+		return nil, invalidFormat(pgio.ErrValueOverflow)
+	}
+
+	buf := pgio.NewBuffer(nil)
+	buf.Grow(sizeMessageKind + length)
+	buf.AppendByte(byte(MessageKindAuthentication))
+	buf.AppendInt32(int32(length))
+	buf.AppendByte(body...)
+
+	frame := buf.Bytes()
+
+	m, err := newAuthentication(frame[sizeMessageKind:])
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.UnmarshalBinary(frame)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _ Message = &MsgAuthenticationOk{}
 var _ Backend = &MsgAuthenticationOk{}
 
@@ -12,6 +43,10 @@ type MsgAuthenticationOk struct{}
 
 func (x *MsgAuthenticationOk) message() {}
 
+func (x *MsgAuthenticationOk) Kind() MessageKind {
+	return MessageKindAuthentication
+}
+
 func (x *MsgAuthenticationOk) backend() {}
 
 func (x *MsgAuthenticationOk) AppendBinary(b []byte) ([]byte, error) {
@@ -54,6 +89,10 @@ type MsgAuthenticationKerberosV5 struct{}
 
 func (x *MsgAuthenticationKerberosV5) message() {}
 
+func (x *MsgAuthenticationKerberosV5) Kind() MessageKind {
+	return MessageKindAuthentication
+}
+
 func (x *MsgAuthenticationKerberosV5) backend() {}
 
 func (x *MsgAuthenticationKerberosV5) AppendBinary(b []byte) ([]byte, error) {
@@ -96,6 +135,10 @@ type MsgAuthenticationCleartextPassword struct{}
 
 func (x *MsgAuthenticationCleartextPassword) message() {}
 
+func (x *MsgAuthenticationCleartextPassword) Kind() MessageKind {
+	return MessageKindAuthentication
+}
+
 func (x *MsgAuthenticationCleartextPassword) backend() {}
 
 func (x *MsgAuthenticationCleartextPassword) AppendBinary(b []byte) ([]byte, error) {
@@ -140,6 +183,10 @@ type MsgAuthenticationMD5Password struct {
 
 func (x *MsgAuthenticationMD5Password) message() {}
 
+func (x *MsgAuthenticationMD5Password) Kind() MessageKind {
+	return MessageKindAuthentication
+}
+
 func (x *MsgAuthenticationMD5Password) backend() {}
 
 func (x *MsgAuthenticationMD5Password) AppendBinary(b []byte) ([]byte, error) {
@@ -170,10 +217,32 @@ func (x *MsgAuthenticationMD5Password) UnmarshalBinary(b []byte) error {
 	if !AuthenticationKindMD5Password.Is(authKind) {
 		return unexpectedAuthKind(authKind, AuthenticationKindMD5Password)
 	}
+
+	if len(b) < len(x.Salt) {
+		return invalidFormat(pgio.ErrValueUnderflow)
+	}
+
+	if len(b) > len(x.Salt) {
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
 	copy(x.Salt[:], b)
 	return nil
 }
 
+// NewAuthenticationMD5Password returns an MD5 password request with a
+// random salt.
+func NewAuthenticationMD5Password() *MsgAuthenticationMD5Password {
+	x := &MsgAuthenticationMD5Password{}
+	_, _ = rand.Read(x.Salt[:])
+	return x
+}
+
+// NewAuthenticationMD5PasswordSalt returns an MD5 password request with the
+// given salt, for tests that need a predictable response.
+func NewAuthenticationMD5PasswordSalt(salt [4]byte) *MsgAuthenticationMD5Password {
+	return &MsgAuthenticationMD5Password{Salt: salt}
+}
+
 var _ Message = &MsgAuthenticationGSS{}
 var _ Backend = &MsgAuthenticationGSS{}
 
@@ -181,6 +250,10 @@ type MsgAuthenticationGSS struct{}
 
 func (x *MsgAuthenticationGSS) message() {}
 
+func (x *MsgAuthenticationGSS) Kind() MessageKind {
+	return MessageKindAuthentication
+}
+
 func (x *MsgAuthenticationGSS) backend() {}
 
 func (x *MsgAuthenticationGSS) AppendBinary(b []byte) ([]byte, error) {
@@ -224,6 +297,10 @@ type MsgAuthenticationGSSContinue struct {
 
 func (x *MsgAuthenticationGSSContinue) message() {}
 
+func (x *MsgAuthenticationGSSContinue) Kind() MessageKind {
+	return MessageKindAuthentication
+}
+
 func (x *MsgAuthenticationGSSContinue) backend() {}
 
 func (x *MsgAuthenticationGSSContinue) AppendBinary(b []byte) ([]byte, error) {
@@ -271,6 +348,10 @@ type MsgAuthenticationSSPI struct{}
 
 func (x *MsgAuthenticationSSPI) message() {}
 
+func (x *MsgAuthenticationSSPI) Kind() MessageKind {
+	return MessageKindAuthentication
+}
+
 func (x *MsgAuthenticationSSPI) backend() {}
 
 func (x *MsgAuthenticationSSPI) AppendBinary(b []byte) ([]byte, error) {
@@ -315,6 +396,10 @@ type MsgAuthenticationSASL struct {
 
 func (x *MsgAuthenticationSASL) message() {}
 
+func (x *MsgAuthenticationSASL) Kind() MessageKind {
+	return MessageKindAuthentication
+}
+
 func (x *MsgAuthenticationSASL) backend() {}
 
 func (x *MsgAuthenticationSASL) AppendBinary(b []byte) ([]byte, error) {
@@ -388,6 +473,10 @@ type MsgAuthenticationSASLContinue struct {
 
 func (x *MsgAuthenticationSASLContinue) message() {}
 
+func (x *MsgAuthenticationSASLContinue) Kind() MessageKind {
+	return MessageKindAuthentication
+}
+
 func (x *MsgAuthenticationSASLContinue) backend() {}
 
 func (x *MsgAuthenticationSASLContinue) AppendBinary(b []byte) ([]byte, error) {
@@ -438,6 +527,10 @@ type MsgAuthenticationSASLFinal struct {
 
 func (x *MsgAuthenticationSASLFinal) message() {}
 
+func (x *MsgAuthenticationSASLFinal) Kind() MessageKind {
+	return MessageKindAuthentication
+}
+
 func (x *MsgAuthenticationSASLFinal) backend() {}
 
 func (x *MsgAuthenticationSASLFinal) AppendBinary(b []byte) ([]byte, error) {
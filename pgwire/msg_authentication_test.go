@@ -63,6 +63,65 @@ func TestMsgAuthenticationMD5Password(t *testing.T) {
 	})
 }
 
+func TestMsgAuthenticationMD5PasswordSaltLength(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Short", func(t *testing.T) {
+		buf := pgio.NewBuffer(nil)
+		buf.AppendByte(byte(pgwire.MessageKindAuthentication))
+		buf.AppendInt32(11)
+		buf.AppendInt32(int32(pgwire.AuthenticationKindMD5Password))
+		buf.AppendByte([]byte("432")...)
+
+		var m pgwire.MsgAuthenticationMD5Password
+
+		err := m.UnmarshalBinary(buf.Bytes())
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+	})
+
+	t.Run("Long", func(t *testing.T) {
+		buf := pgio.NewBuffer(nil)
+		buf.AppendByte(byte(pgwire.MessageKindAuthentication))
+		buf.AppendInt32(13)
+		buf.AppendInt32(int32(pgwire.AuthenticationKindMD5Password))
+		buf.AppendByte([]byte("43210")...)
+
+		var m pgwire.MsgAuthenticationMD5Password
+
+		err := m.UnmarshalBinary(buf.Bytes())
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueOverflow)
+	})
+}
+
+func TestNewAuthenticationMD5Password(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Random", func(t *testing.T) {
+		t.Parallel()
+
+		a := pgwire.NewAuthenticationMD5Password()
+		b := pgwire.NewAuthenticationMD5Password()
+		require.NotEqual(t, a.Salt, b.Salt)
+	})
+
+	t.Run("Salt", func(t *testing.T) {
+		t.Parallel()
+
+		m := pgwire.NewAuthenticationMD5PasswordSalt([4]byte{1, 2, 3, 4})
+
+		b, err := m.AppendBinary(nil)
+		require.NoError(t, err)
+
+		var got pgwire.MsgAuthenticationMD5Password
+
+		err = got.UnmarshalBinary(b)
+		require.NoError(t, err)
+		require.Equal(t, [4]byte{1, 2, 3, 4}, got.Salt)
+	})
+}
+
 func TestMsgAuthenticationGSS(t *testing.T) {
 	t.Parallel()
 
@@ -153,3 +212,51 @@ func TestMsgAuthenticationSASLFinal(t *testing.T) {
 		require.Equal(t, "hello world", string(m.Data))
 	})
 }
+
+func TestParseAuthentication(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		m    pgwire.Backend
+	}{
+		{"Ok", &pgwire.MsgAuthenticationOk{}},
+		{"KerberosV5", &pgwire.MsgAuthenticationKerberosV5{}},
+		{"CleartextPassword", &pgwire.MsgAuthenticationCleartextPassword{}},
+		{"MD5Password", &pgwire.MsgAuthenticationMD5Password{Salt: [4]byte{1, 2, 3, 4}}},
+		{"GSS", &pgwire.MsgAuthenticationGSS{}},
+		{"GSSContinue", &pgwire.MsgAuthenticationGSSContinue{Data: []byte("token")}},
+		{"SSPI", &pgwire.MsgAuthenticationSSPI{}},
+		{"SASL", &pgwire.MsgAuthenticationSASL{Mechanisms: []string{"SCRAM-SHA-256"}}},
+		{"SASLContinue", &pgwire.MsgAuthenticationSASLContinue{Data: []byte("r=abc")}},
+		{"SASLFinal", &pgwire.MsgAuthenticationSASLFinal{Data: []byte("v=xyz")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := tt.m.AppendBinary(nil)
+			require.NoError(t, err)
+
+			m, err := pgwire.ParseAuthentication(b[5:])
+			require.NoError(t, err)
+			require.Equal(t, tt.m, m)
+		})
+	}
+
+	t.Run("Short", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := pgwire.ParseAuthentication([]byte{0, 0})
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := pgwire.ParseAuthentication([]byte{0, 0, 0, 99})
+		require.ErrorIs(t, err, pgio.ErrUnknownAuthType)
+	})
+}
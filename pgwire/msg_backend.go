@@ -1,8 +1,15 @@
 package pgwire
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
 	"gopsql/pgio"
 	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 var _ Message = &MsgBackendKeyData{}
@@ -15,6 +22,10 @@ type MsgBackendKeyData struct {
 
 func (x *MsgBackendKeyData) message() {}
 
+func (x *MsgBackendKeyData) Kind() MessageKind {
+	return MessageKindBackendKeyData
+}
+
 func (x *MsgBackendKeyData) backend() {}
 
 func (x *MsgBackendKeyData) AppendBinary(b []byte) ([]byte, error) {
@@ -74,6 +85,23 @@ func (x *MsgBackendKeyData) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// SecretKeyUint32 returns the secret key as the Int32 of protocol 3.0, or
+// false when it is not exactly 4 bytes long.
+func (x *MsgBackendKeyData) SecretKeyUint32() (uint32, bool) {
+	return secretKeyUint32(x.SecretKey)
+}
+
+// SetSecretKeyUint32 sets the secret key to the Int32 form of protocol 3.0.
+func (x *MsgBackendKeyData) SetSecretKeyUint32(key uint32) {
+	x.SecretKey = binary.BigEndian.AppendUint32(nil, key)
+}
+
+// Validate checks the secret key length against the negotiated protocol
+// version: exactly 4 bytes before 3.2, and 4 to 256 bytes from 3.2 on.
+func (x *MsgBackendKeyData) Validate(version ProtocolVersion) error {
+	return validateSecretKey(x.SecretKey, version)
+}
+
 var _ Message = &MsgBindComplete{}
 var _ Backend = &MsgBindComplete{}
 
@@ -81,6 +109,10 @@ type MsgBindComplete struct{}
 
 func (x *MsgBindComplete) message() {}
 
+func (x *MsgBindComplete) Kind() MessageKind {
+	return MessageKindBindComplete
+}
+
 func (x *MsgBindComplete) backend() {}
 
 func (x *MsgBindComplete) AppendBinary(b []byte) ([]byte, error) {
@@ -113,6 +145,10 @@ type MsgCloseComplete struct{}
 
 func (x *MsgCloseComplete) message() {}
 
+func (x *MsgCloseComplete) Kind() MessageKind {
+	return MessageKindCloseComplete
+}
+
 func (x *MsgCloseComplete) backend() {}
 
 func (x *MsgCloseComplete) AppendBinary(b []byte) ([]byte, error) {
@@ -147,6 +183,10 @@ type MsgCommandComplete struct {
 
 func (x *MsgCommandComplete) message() {}
 
+func (x *MsgCommandComplete) Kind() MessageKind {
+	return MessageKindCommandComplete
+}
+
 func (x *MsgCommandComplete) backend() {}
 
 func (x *MsgCommandComplete) AppendBinary(b []byte) ([]byte, error) {
@@ -193,6 +233,10 @@ type MsgCopyInResponse struct {
 
 func (x *MsgCopyInResponse) message() {}
 
+func (x *MsgCopyInResponse) Kind() MessageKind {
+	return MessageKindCopyInResponse
+}
+
 func (x *MsgCopyInResponse) backend() {}
 
 func (x *MsgCopyInResponse) AppendBinary(b []byte) ([]byte, error) {
@@ -247,6 +291,11 @@ func (x *MsgCopyInResponse) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
+	err = checkCount(buf, int(length), 2)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
 	columns := make([]int16, 0, length)
 
 	for range length {
@@ -271,6 +320,10 @@ type MsgCopyOutResponse struct {
 
 func (x *MsgCopyOutResponse) message() {}
 
+func (x *MsgCopyOutResponse) Kind() MessageKind {
+	return MessageKindCopyOutResponse
+}
+
 func (x *MsgCopyOutResponse) backend() {}
 
 func (x *MsgCopyOutResponse) AppendBinary(b []byte) ([]byte, error) {
@@ -325,6 +378,11 @@ func (x *MsgCopyOutResponse) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
+	err = checkCount(buf, int(length), 2)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
 	columns := make([]int16, 0, length)
 
 	for range length {
@@ -349,6 +407,10 @@ type MsgCopyBothResponse struct {
 
 func (x *MsgCopyBothResponse) message() {}
 
+func (x *MsgCopyBothResponse) Kind() MessageKind {
+	return MessageKindCopyBothResponse
+}
+
 func (x *MsgCopyBothResponse) backend() {}
 
 func (x *MsgCopyBothResponse) AppendBinary(b []byte) ([]byte, error) {
@@ -403,6 +465,11 @@ func (x *MsgCopyBothResponse) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
+	err = checkCount(buf, int(length), 2)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
 	columns := make([]int16, 0, length)
 
 	for range length {
@@ -429,6 +496,10 @@ type MsgDataRow struct {
 
 func (x *MsgDataRow) message() {}
 
+func (x *MsgDataRow) Kind() MessageKind {
+	return MessageKindDataRow
+}
+
 func (x *MsgDataRow) backend() {}
 
 func (x *MsgDataRow) AppendBinary(b []byte) ([]byte, error) {
@@ -481,7 +552,24 @@ func (x *MsgDataRow) AppendBinary(b []byte) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// UnmarshalBinary ignores any bytes that follow the last column. Use
+// UnmarshalBinaryStrict to reject them.
+//
+// The columns are copied from b into the buffers x already holds when they
+// are large enough, so decoding a stream of rows into one MsgDataRow does
+// not allocate once the buffers have grown. Columns from a previous decode
+// are only valid until the next one; use Clone to keep them.
 func (x *MsgDataRow) UnmarshalBinary(b []byte) error {
+	return x.unmarshal(b, false)
+}
+
+// UnmarshalBinaryStrict is like UnmarshalBinary, but a body with bytes after
+// the last column is an error.
+func (x *MsgDataRow) UnmarshalBinaryStrict(b []byte) error {
+	return x.unmarshal(b, true)
+}
+
+func (x *MsgDataRow) unmarshal(b []byte, strict bool) error {
 	b, err := shiftHeader(MessageKindDataRow, b)
 	if err != nil {
 		return invalidFormat(err)
@@ -493,30 +581,57 @@ func (x *MsgDataRow) UnmarshalBinary(b []byte) error {
 	if err != nil {
 		return invalidFormat(err)
 	}
-	columns := make([][]byte, 0, countCols)
 
-	for range countCols {
-		length, err := buf.ShiftInt32()
-		if err != nil {
-			return invalidFormat(err)
-		}
+	err = checkCount(buf, int(countCols), 4)
+	if err != nil {
+		return invalidFormat(err)
+	}
 
-		if length == -1 {
-			columns = append(columns, nil)
-			continue
-		}
+	columns := x.Columns[:0]
+	if cap(columns) < int(countCols) {
+		columns = make([][]byte, 0, countCols)
+	}
+
+	// The column buffers left by the previous decode, reused in place. Once
+	// they are being overwritten, an error empties x rather than leave it
+	// holding a mix of two rows.
+	prev := columns[:cap(columns)]
 
-		data, err := buf.ShiftBytes(int(length))
+	for i := range int(countCols) {
+		data, err := shiftNullableBytesInto(buf, prev[i])
 		if err != nil {
+			x.Reset()
 			return invalidFormat(err)
 		}
 		columns = append(columns, data)
 	}
 
+	if strict && buf.Len() > 0 {
+		x.Reset()
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
+
 	x.Columns = columns
 	return nil
 }
 
+// Reset empties x but keeps its buffers for the next UnmarshalBinary.
+func (x *MsgDataRow) Reset() {
+	x.Columns = x.Columns[:0]
+}
+
+// Clone returns a deep copy of x.
+func (x *MsgDataRow) Clone() *MsgDataRow {
+	columns := make([][]byte, len(x.Columns))
+
+	for i, column := range x.Columns {
+		if column != nil {
+			columns[i] = bytes.Clone(column)
+		}
+	}
+	return &MsgDataRow{Columns: columns}
+}
+
 var _ Message = &MsgEmptyQueryResponse{}
 var _ Backend = &MsgEmptyQueryResponse{}
 
@@ -524,6 +639,10 @@ type MsgEmptyQueryResponse struct{}
 
 func (x *MsgEmptyQueryResponse) message() {}
 
+func (x *MsgEmptyQueryResponse) Kind() MessageKind {
+	return MessageKindEmptyQueryResponse
+}
+
 func (x *MsgEmptyQueryResponse) backend() {}
 
 func (x *MsgEmptyQueryResponse) AppendBinary(b []byte) ([]byte, error) {
@@ -552,6 +671,9 @@ func (x *MsgEmptyQueryResponse) UnmarshalBinary(b []byte) error {
 var _ Message = &MsgErrorResponse{}
 var _ Backend = &MsgErrorResponse{}
 
+// MsgErrorResponse holds the error fields in parallel slices. The encoded
+// field list is always followed by a terminating zero byte, so a response
+// without any fields encodes to a body of a single zero byte.
 type MsgErrorResponse struct {
 	Fields []byte
 	Values []string
@@ -559,6 +681,10 @@ type MsgErrorResponse struct {
 
 func (x *MsgErrorResponse) message() {}
 
+func (x *MsgErrorResponse) Kind() MessageKind {
+	return MessageKindErrorResponse
+}
+
 func (x *MsgErrorResponse) backend() {}
 
 func (x *MsgErrorResponse) AppendBinary(b []byte) ([]byte, error) {
@@ -567,6 +693,10 @@ func (x *MsgErrorResponse) AppendBinary(b []byte) ([]byte, error) {
 	countFields := len(x.Fields)
 	countValues := len(x.Values)
 
+	if countFields != countValues {
+		return b, invalidFormat(pgio.ErrValueOverflow)
+	}
+
 	sizeFields := countFields * sizeField
 
 	length := sizeMessageLength + sizeFields
@@ -594,7 +724,7 @@ func (x *MsgErrorResponse) AppendBinary(b []byte) ([]byte, error) {
 		buf.AppendString(x.Values[i])
 	}
 	buf.AppendByte(0)
-	return b, nil
+	return buf.Bytes(), nil
 }
 
 func (x *MsgErrorResponse) UnmarshalBinary(b []byte) error {
@@ -633,6 +763,162 @@ func (x *MsgErrorResponse) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// Clone returns a deep copy of x whose strings no longer share memory with
+// the frame x was decoded from.
+func (x *MsgErrorResponse) Clone() *MsgErrorResponse {
+	return &MsgErrorResponse{
+		Fields: bytes.Clone(x.Fields),
+		Values: cloneStrings(x.Values),
+	}
+}
+
+// Error formats x as in `ERROR: relation "t" does not exist (SQLSTATE
+// 42P01)`. A missing Severity or Code is left out, and the Position is
+// included only when the server reported one.
+func (x *MsgErrorResponse) Error() string {
+	var sb strings.Builder
+
+	if severity, ok := x.Severity(); ok {
+		sb.WriteString(severity)
+		sb.WriteString(": ")
+	}
+
+	message, _ := x.Message()
+	sb.WriteString(message)
+
+	if position, ok := x.Position(); ok {
+		sb.WriteString(" at position ")
+		sb.WriteString(position)
+	}
+
+	if code, ok := x.Code(); ok {
+		sb.WriteString(" (SQLSTATE ")
+		sb.WriteString(code)
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+// Get returns the value of field. When a field appears more than once, the
+// first occurrence wins.
+func (x *MsgErrorResponse) Get(field FieldKind) (string, bool) {
+	return lookupField(x.Fields, x.Values, field)
+}
+
+// With sets field to value, replacing its first occurrence or appending it
+// when absent, and returns x so that responses can be built in one
+// expression.
+func (x *MsgErrorResponse) With(field FieldKind, value string) *MsgErrorResponse {
+	x.Fields, x.Values = setField(x.Fields, x.Values, field, value)
+	return x
+}
+
+func (x *MsgErrorResponse) Severity() (string, bool) {
+	return x.Get(FieldKindSeverity)
+}
+
+func (x *MsgErrorResponse) Code() (string, bool) {
+	return x.Get(FieldKindCode)
+}
+
+func (x *MsgErrorResponse) Message() (string, bool) {
+	return x.Get(FieldKindMessage)
+}
+
+func (x *MsgErrorResponse) Detail() (string, bool) {
+	return x.Get(FieldKindDetail)
+}
+
+func (x *MsgErrorResponse) Hint() (string, bool) {
+	return x.Get(FieldKindHint)
+}
+
+func (x *MsgErrorResponse) Position() (string, bool) {
+	return x.Get(FieldKindPosition)
+}
+
+func (x *MsgErrorResponse) Schema() (string, bool) {
+	return x.Get(FieldKindSchema)
+}
+
+func (x *MsgErrorResponse) Table() (string, bool) {
+	return x.Get(FieldKindTable)
+}
+
+func (x *MsgErrorResponse) Column() (string, bool) {
+	return x.Get(FieldKindColumn)
+}
+
+func (x *MsgErrorResponse) Constraint() (string, bool) {
+	return x.Get(FieldKindConstraint)
+}
+
+func (x *MsgErrorResponse) SourceFile() (string, bool) {
+	return lookupField(x.Fields, x.Values, FieldKindFile)
+}
+
+// SourceLine returns the line number in the server source code where the
+// error was reported.
+func (x *MsgErrorResponse) SourceLine() (int, bool) {
+	value, ok := lookupField(x.Fields, x.Values, FieldKindLine)
+	if !ok {
+		return 0, false
+	}
+
+	line, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return line, true
+}
+
+func (x *MsgErrorResponse) SourceRoutine() (string, bool) {
+	return lookupField(x.Fields, x.Values, FieldKindRoutine)
+}
+
+// SQLStateClass returns the two character class of the SQLSTATE code, or an
+// empty string when the code is absent or malformed.
+func (x *MsgErrorResponse) SQLStateClass() string {
+	code, ok := lookupField(x.Fields, x.Values, FieldKindCode)
+	if !ok || len(code) < 2 {
+		return ""
+	}
+	return code[:2]
+}
+
+func (x *MsgErrorResponse) IsConnectionException() bool {
+	return x.SQLStateClass() == "08"
+}
+
+func (x *MsgErrorResponse) IsIntegrityConstraintViolation() bool {
+	return x.SQLStateClass() == "23"
+}
+
+func (x *MsgErrorResponse) IsTransactionRollback() bool {
+	return x.SQLStateClass() == "40"
+}
+
+// ErrorContext identifies the database object associated with an error.
+// Fields the server did not report are left empty.
+type ErrorContext struct {
+	Schema     string
+	Table      string
+	Column     string
+	DataType   string
+	Constraint string
+}
+
+func (x *MsgErrorResponse) Context() ErrorContext {
+	var ctx ErrorContext
+
+	ctx.Schema, _ = x.Schema()
+	ctx.Table, _ = x.Table()
+	ctx.Column, _ = x.Column()
+	ctx.DataType, _ = x.Get(FieldKindDataType)
+	ctx.Constraint, _ = x.Constraint()
+	return ctx
+}
+
 var _ Message = &MsgFunctionCallResponse{}
 var _ Backend = &MsgFunctionCallResponse{}
 
@@ -643,6 +929,10 @@ type MsgFunctionCallResponse struct {
 
 func (x *MsgFunctionCallResponse) message() {}
 
+func (x *MsgFunctionCallResponse) Kind() MessageKind {
+	return MessageKindFunctionCallResponse
+}
+
 func (x *MsgFunctionCallResponse) backend() {}
 
 func (x *MsgFunctionCallResponse) AppendBinary(b []byte) ([]byte, error) {
@@ -679,16 +969,11 @@ func (x *MsgFunctionCallResponse) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
-	length, b, err := pgio.ShiftInt32(b)
+	result, err := shiftNullableBytes(pgio.NewBuffer(b))
 	if err != nil {
 		return invalidFormat(err)
 	}
-
-	if length >= 0 {
-		x.Result = make([]byte, length)
-		copy(x.Result, b)
-	}
-	// Result remains nil when length < 0
+	x.Result = result
 	return nil
 }
 
@@ -702,6 +987,10 @@ type MsgNegotiateProtocolVersion struct {
 
 func (x *MsgNegotiateProtocolVersion) message() {}
 
+func (x *MsgNegotiateProtocolVersion) Kind() MessageKind {
+	return MessageKindNegotiateProtocolVersion
+}
+
 func (x *MsgNegotiateProtocolVersion) backend() {}
 
 func (x *MsgNegotiateProtocolVersion) AppendBinary(b []byte) ([]byte, error) {
@@ -719,7 +1008,7 @@ func (x *MsgNegotiateProtocolVersion) AppendBinary(b []byte) ([]byte, error) {
 	}
 
 	for _, option := range x.UnrecognizedOptions {
-		length += len(option)
+		length += len(option) + 1 // null terminated string
 	}
 
 	if length > math.MaxInt32 {
@@ -756,7 +1045,11 @@ func (x *MsgNegotiateProtocolVersion) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
-	options := make([]string, 0, countUnsupportedOptions)
+	if countUnsupportedOptions < 0 {
+		return invalidFormat(pgio.ErrValueUnderflow)
+	}
+
+	var options []string
 
 	for range countUnsupportedOptions {
 		option, err := buf.ShiftString()
@@ -770,6 +1063,19 @@ func (x *MsgNegotiateProtocolVersion) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// ShouldRetry reports whether the connection can continue without the
+// options the server did not recognize, that is, none of them is in
+// required. When it returns false the client should give up or reconnect
+// without relying on those options.
+func (x *MsgNegotiateProtocolVersion) ShouldRetry(required []string) bool {
+	for _, option := range x.UnrecognizedOptions {
+		if slices.Contains(required, option) {
+			return false
+		}
+	}
+	return true
+}
+
 var _ Message = &MsgNoData{}
 var _ Backend = &MsgNoData{}
 
@@ -777,6 +1083,10 @@ type MsgNoData struct{}
 
 func (x *MsgNoData) message() {}
 
+func (x *MsgNoData) Kind() MessageKind {
+	return MessageKindNoData
+}
+
 func (x *MsgNoData) backend() {}
 
 func (x *MsgNoData) AppendBinary(b []byte) ([]byte, error) {
@@ -812,6 +1122,10 @@ type MsgNoticeResponse struct {
 
 func (x *MsgNoticeResponse) message() {}
 
+func (x *MsgNoticeResponse) Kind() MessageKind {
+	return MessageKindNoticeResponse
+}
+
 func (x *MsgNoticeResponse) backend() {}
 
 func (x *MsgNoticeResponse) AppendBinary(b []byte) ([]byte, error) {
@@ -882,6 +1196,83 @@ func (x *MsgNoticeResponse) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// Clone returns a deep copy of x whose strings no longer share memory with
+// the frame x was decoded from.
+func (x *MsgNoticeResponse) Clone() *MsgNoticeResponse {
+	return &MsgNoticeResponse{
+		Fields: bytes.Clone(x.Fields),
+		Values: cloneStrings(x.Values),
+	}
+}
+
+// Get returns the value of field. When a field appears more than once, the
+// first occurrence wins.
+func (x *MsgNoticeResponse) Get(field FieldKind) (string, bool) {
+	return lookupField(x.Fields, x.Values, field)
+}
+
+// With sets field to value, replacing its first occurrence or appending it
+// when absent, and returns x.
+func (x *MsgNoticeResponse) With(field FieldKind, value string) *MsgNoticeResponse {
+	x.Fields, x.Values = setField(x.Fields, x.Values, field, value)
+	return x
+}
+
+func (x *MsgNoticeResponse) Severity() (string, bool) {
+	return x.Get(FieldKindSeverity)
+}
+
+func (x *MsgNoticeResponse) Code() (string, bool) {
+	return x.Get(FieldKindCode)
+}
+
+func (x *MsgNoticeResponse) Message() (string, bool) {
+	return x.Get(FieldKindMessage)
+}
+
+func (x *MsgNoticeResponse) Detail() (string, bool) {
+	return x.Get(FieldKindDetail)
+}
+
+func (x *MsgNoticeResponse) Hint() (string, bool) {
+	return x.Get(FieldKindHint)
+}
+
+func (x *MsgNoticeResponse) Position() (string, bool) {
+	return x.Get(FieldKindPosition)
+}
+
+func (x *MsgNoticeResponse) Schema() (string, bool) {
+	return x.Get(FieldKindSchema)
+}
+
+func (x *MsgNoticeResponse) Table() (string, bool) {
+	return x.Get(FieldKindTable)
+}
+
+func (x *MsgNoticeResponse) Column() (string, bool) {
+	return x.Get(FieldKindColumn)
+}
+
+func (x *MsgNoticeResponse) Constraint() (string, bool) {
+	return x.Get(FieldKindConstraint)
+}
+
+// SeverityLevel classifies the notice. The non-localized SeverityRaw field
+// is preferred; without it the Severity field is used, which the server
+// translates into the client's language and so may not be recognized.
+func (x *MsgNoticeResponse) SeverityLevel() SeverityLevel {
+	severity, ok := x.Get(FieldKindSeverityRaw)
+	if !ok {
+		severity, _ = x.Get(FieldKindSeverity)
+	}
+	return ParseSeverityLevel(severity)
+}
+
+func (x *MsgNoticeResponse) IsWarning() bool {
+	return x.SeverityLevel() == SeverityLevelWarning
+}
+
 var _ Message = &MsgNotificationResponse{}
 var _ Backend = &MsgNotificationResponse{}
 
@@ -893,6 +1284,10 @@ type MsgNotificationResponse struct {
 
 func (x *MsgNotificationResponse) message() {}
 
+func (x *MsgNotificationResponse) Kind() MessageKind {
+	return MessageKindNotificationResponse
+}
+
 func (x *MsgNotificationResponse) backend() {}
 
 func (x *MsgNotificationResponse) AppendBinary(b []byte) ([]byte, error) {
@@ -916,6 +1311,7 @@ func (x *MsgNotificationResponse) AppendBinary(b []byte) ([]byte, error) {
 	buf.Grow(size)
 	buf.AppendByte(byte(MessageKindNotificationResponse))
 	buf.AppendInt32(int32(length))
+	buf.AppendInt32(x.ProcessID)
 	buf.AppendString(x.Channel)
 	buf.AppendString(x.Payload)
 	return buf.Bytes(), nil
@@ -959,6 +1355,10 @@ type MsgParameterDescription struct {
 
 func (x *MsgParameterDescription) message() {}
 
+func (x *MsgParameterDescription) Kind() MessageKind {
+	return MessageKindParameterDescription
+}
+
 func (x *MsgParameterDescription) backend() {}
 
 func (x *MsgParameterDescription) AppendBinary(b []byte) ([]byte, error) {
@@ -1002,6 +1402,11 @@ func (x *MsgParameterDescription) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
+	err = checkCount(buf, int(countParameters), 4)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
 	parameters := make([]int32, 0, countParameters)
 	for range countParameters {
 		parameter, err := buf.ShiftInt32()
@@ -1024,6 +1429,10 @@ type MsgParameterStatus struct {
 
 func (x *MsgParameterStatus) message() {}
 
+func (x *MsgParameterStatus) Kind() MessageKind {
+	return MessageKindParameterStatus
+}
+
 func (x *MsgParameterStatus) backend() {}
 
 func (x *MsgParameterStatus) AppendBinary(b []byte) ([]byte, error) {
@@ -1073,6 +1482,19 @@ func (x *MsgParameterStatus) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// Validate reports a ParameterStatus PostgreSQL would never send: one with
+// an empty name, or a name or value that is not valid UTF-8.
+func (x *MsgParameterStatus) Validate() error {
+	if x.Name == "" {
+		return fmt.Errorf("%w: empty parameter name", ErrInvalidFormat)
+	}
+
+	if !utf8.ValidString(x.Name) || !utf8.ValidString(x.Value) {
+		return fmt.Errorf("%w: parameter %q is not valid UTF-8", ErrInvalidFormat, x.Name)
+	}
+	return nil
+}
+
 var _ Message = &MsgParseComplete{}
 var _ Backend = &MsgParseComplete{}
 
@@ -1080,6 +1502,10 @@ type MsgParseComplete struct{}
 
 func (x *MsgParseComplete) message() {}
 
+func (x *MsgParseComplete) Kind() MessageKind {
+	return MessageKindParseComplete
+}
+
 func (x *MsgParseComplete) backend() {}
 
 func (x *MsgParseComplete) AppendBinary(b []byte) ([]byte, error) {
@@ -1113,6 +1539,10 @@ type MsgPortalSuspended struct{}
 
 func (x *MsgPortalSuspended) message() {}
 
+func (x *MsgPortalSuspended) Kind() MessageKind {
+	return MessageKindPortalSuspend
+}
+
 func (x *MsgPortalSuspended) backend() {}
 
 func (x *MsgPortalSuspended) AppendBinary(b []byte) ([]byte, error) {
@@ -1148,6 +1578,10 @@ type MsgReadyForQuery struct {
 
 func (x *MsgReadyForQuery) message() {}
 
+func (x *MsgReadyForQuery) Kind() MessageKind {
+	return MessageKindReadyForQuery
+}
+
 func (x *MsgReadyForQuery) backend() {}
 
 func (x *MsgReadyForQuery) AppendBinary(b []byte) ([]byte, error) {
@@ -1196,8 +1630,80 @@ type MsgRowDescription struct {
 	Formats   []int16
 }
 
+// ColumnDescription describes one column of a RowDescription.
+type ColumnDescription struct {
+	Name     string
+	Table    int32
+	Column   int16
+	DataType int32
+	Size     int16
+	Modifier int32
+	// Format is zero, the text format, unless set.
+	Format int16
+}
+
+// NewRowDescription builds a RowDescription from per-column descriptions,
+// keeping its parallel slices the same length.
+func NewRowDescription(columns []ColumnDescription) *MsgRowDescription {
+	n := len(columns)
+
+	x := &MsgRowDescription{
+		Names:     make([]string, n),
+		Tables:    make([]int32, n),
+		Columns:   make([]int16, n),
+		DataTypes: make([]int32, n),
+		Sizes:     make([]int16, n),
+		Modifiers: make([]int32, n),
+		Formats:   make([]int16, n),
+	}
+
+	for i, column := range columns {
+		x.Names[i] = column.Name
+		x.Tables[i] = column.Table
+		x.Columns[i] = column.Column
+		x.DataTypes[i] = column.DataType
+		x.Sizes[i] = column.Size
+		x.Modifiers[i] = column.Modifier
+		x.Formats[i] = column.Format
+	}
+	return x
+}
+
+// ColumnDescriptions returns x one column at a time, the inverse of
+// NewRowDescription. Should the parallel slices differ in length, only the
+// columns present in all of them are returned.
+func (x *MsgRowDescription) ColumnDescriptions() []ColumnDescription {
+	n := min(len(x.Names), len(x.Tables), len(x.Columns), len(x.DataTypes),
+		len(x.Sizes), len(x.Modifiers), len(x.Formats))
+
+	columns := make([]ColumnDescription, n)
+
+	for i := range columns {
+		columns[i] = ColumnDescription{
+			Name:     x.Names[i],
+			Table:    x.Tables[i],
+			Column:   x.Columns[i],
+			DataType: x.DataTypes[i],
+			Size:     x.Sizes[i],
+			Modifier: x.Modifiers[i],
+			Format:   x.Formats[i],
+		}
+	}
+	return columns
+}
+
+// Index returns the position of the first column called name.
+func (x *MsgRowDescription) Index(name string) (int, bool) {
+	i := slices.Index(x.Names, name)
+	return i, i >= 0
+}
+
 func (x *MsgRowDescription) message() {}
 
+func (x *MsgRowDescription) Kind() MessageKind {
+	return MessageKindRowDescription
+}
+
 func (x *MsgRowDescription) backend() {}
 
 func (x *MsgRowDescription) AppendBinary(b []byte) ([]byte, error) {
@@ -1224,6 +1730,15 @@ func (x *MsgRowDescription) AppendBinary(b []byte) ([]byte, error) {
 		return b, invalidFormat(pgio.ErrValueOverflow)
 	}
 
+	if len(x.Tables) != countFields ||
+		len(x.Columns) != countFields ||
+		len(x.DataTypes) != countFields ||
+		len(x.Sizes) != countFields ||
+		len(x.Modifiers) != countFields ||
+		len(x.Formats) != countFields {
+		return b, invalidFormat(pgio.ErrInvalidValue)
+	}
+
 	var sizeRows int
 
 	for i := range countFields {
@@ -1271,6 +1786,12 @@ func (x *MsgRowDescription) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
+	// A field is at least an empty name followed by 18 bytes of attributes.
+	err = checkCount(buf, int(countFields), 19)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
 	names := make([]string, 0, countFields)
 	tables := make([]int32, 0, countFields)
 	columns := make([]int16, 0, countFields)
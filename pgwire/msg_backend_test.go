@@ -1,6 +1,7 @@
 package pgwire_test
 
 import (
+	"bytes"
 	"gopsql/pgio"
 	"gopsql/pgwire"
 	"testing"
@@ -25,6 +26,45 @@ func TestMsgBackendKeyData(t *testing.T) {
 	})
 }
 
+func TestMsgBackendKeyDataSecretKey(t *testing.T) {
+	t.Parallel()
+
+	v3_0 := pgwire.ProtocolVersion(pgwire.ProtocolVersion3_0)
+	v3_2 := pgwire.ProtocolVersion(pgwire.ProtocolVersion3_2)
+
+	var m pgwire.MsgBackendKeyData
+
+	m.SetSecretKeyUint32(0xdeadbeef)
+	require.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, m.SecretKey)
+
+	key, ok := m.SecretKeyUint32()
+	require.True(t, ok)
+	require.Equal(t, uint32(0xdeadbeef), key)
+	require.NoError(t, m.Validate(v3_0))
+	require.NoError(t, m.Validate(v3_2))
+
+	m.SecretKey = make([]byte, 32)
+
+	_, ok = m.SecretKeyUint32()
+	require.False(t, ok)
+	require.ErrorIs(t, m.Validate(v3_0), pgwire.ErrInvalidFormat)
+	require.NoError(t, m.Validate(v3_2))
+
+	m.SecretKey = []byte{1, 2, 3}
+	require.ErrorIs(t, m.Validate(v3_0), pgwire.ErrInvalidFormat)
+	require.ErrorIs(t, m.Validate(v3_2), pgwire.ErrInvalidFormat)
+
+	_, err := m.AppendBinary(nil)
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindBackendKeyData))
+	buf.AppendInt32(11)
+	buf.AppendInt32(4321)
+	buf.AppendByte(1, 2, 3)
+	require.ErrorIs(t, m.UnmarshalBinary(buf.Bytes()), pgwire.ErrInvalidFormat)
+}
+
 func TestMsgBindComplete(t *testing.T) {
 	t.Parallel()
 
@@ -170,3 +210,831 @@ func TestMsgEmptyQueryResponse(t *testing.T) {
 
 	testMessage(t, buf.Bytes(), &m, nil)
 }
+
+func TestMsgErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindErrorResponse))
+	buf.AppendInt32(25)
+	buf.AppendByte(byte(pgwire.FieldKindSeverity))
+	buf.AppendString("ERROR")
+	buf.AppendByte(byte(pgwire.FieldKindCode))
+	buf.AppendString("42P01")
+	buf.AppendByte(byte(pgwire.FieldKindMessage))
+	buf.AppendString("oops")
+	buf.AppendByte(0)
+
+	var m pgwire.MsgErrorResponse
+
+	testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+		require.Equal(t, []byte{
+			byte(pgwire.FieldKindSeverity),
+			byte(pgwire.FieldKindCode),
+			byte(pgwire.FieldKindMessage),
+		}, m.Fields)
+		require.Equal(t, []string{"ERROR", "42P01", "oops"}, m.Values)
+	})
+}
+
+func TestMsgErrorResponseEmpty(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindErrorResponse))
+	buf.AppendInt32(5)
+	buf.AppendByte(0)
+
+	var m pgwire.MsgErrorResponse
+
+	testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+		require.Empty(t, m.Fields)
+		require.Empty(t, m.Values)
+	})
+}
+
+func TestMsgErrorResponseSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Present", func(t *testing.T) {
+		m := pgwire.MsgErrorResponse{
+			Fields: []byte{
+				byte(pgwire.FieldKindSeverity),
+				byte(pgwire.FieldKindFile),
+				byte(pgwire.FieldKindLine),
+				byte(pgwire.FieldKindRoutine),
+			},
+			Values: []string{"ERROR", "parse_relation.c", "1392", "parserOpenTable"},
+		}
+
+		file, ok := m.SourceFile()
+		require.True(t, ok)
+		require.Equal(t, "parse_relation.c", file)
+
+		line, ok := m.SourceLine()
+		require.True(t, ok)
+		require.Equal(t, 1392, line)
+
+		routine, ok := m.SourceRoutine()
+		require.True(t, ok)
+		require.Equal(t, "parserOpenTable", routine)
+	})
+
+	t.Run("Absent", func(t *testing.T) {
+		m := pgwire.MsgErrorResponse{
+			Fields: []byte{byte(pgwire.FieldKindSeverity)},
+			Values: []string{"ERROR"},
+		}
+
+		_, ok := m.SourceFile()
+		require.False(t, ok)
+
+		_, ok = m.SourceLine()
+		require.False(t, ok)
+
+		_, ok = m.SourceRoutine()
+		require.False(t, ok)
+	})
+
+	t.Run("MalformedLine", func(t *testing.T) {
+		m := pgwire.MsgErrorResponse{
+			Fields: []byte{byte(pgwire.FieldKindLine)},
+			Values: []string{"twelve"},
+		}
+
+		_, ok := m.SourceLine()
+		require.False(t, ok)
+	})
+}
+
+func TestMsgErrorResponseContext(t *testing.T) {
+	t.Parallel()
+
+	m := pgwire.MsgErrorResponse{
+		Fields: []byte{
+			byte(pgwire.FieldKindSeverity),
+			byte(pgwire.FieldKindCode),
+			byte(pgwire.FieldKindMessage),
+			byte(pgwire.FieldKindSchema),
+			byte(pgwire.FieldKindTable),
+			byte(pgwire.FieldKindConstraint),
+		},
+		Values: []string{
+			"ERROR",
+			"23505",
+			`duplicate key value violates unique constraint "users_email_key"`,
+			"public",
+			"users",
+			"users_email_key",
+		},
+	}
+
+	require.Equal(t, pgwire.ErrorContext{
+		Schema:     "public",
+		Table:      "users",
+		Constraint: "users_email_key",
+	}, m.Context())
+
+	// As with Get and the accessors, the first occurrence of a field wins.
+	m.Fields = append(m.Fields, byte(pgwire.FieldKindTable))
+	m.Values = append(m.Values, "later")
+
+	table, _ := m.Table()
+	require.Equal(t, "users", table)
+	require.Equal(t, table, m.Context().Table)
+}
+
+func wideDataRow() *pgwire.MsgDataRow {
+	columns := make([][]byte, 50)
+
+	for i := range columns {
+		if i%10 == 0 {
+			continue // NULL
+		}
+		columns[i] = []byte("column value")
+	}
+	return &pgwire.MsgDataRow{Columns: columns}
+}
+
+func TestMsgDataRowWide(t *testing.T) {
+	m := wideDataRow()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindDataRow))
+	buf.AppendInt32(int32(4 + 2 + 50*4 + 45*len("column value")))
+	buf.AppendInt16(50)
+
+	for _, column := range m.Columns {
+		if column == nil {
+			buf.AppendInt32(-1)
+			continue
+		}
+		buf.AppendInt32(int32(len(column)))
+		buf.AppendByte(column...)
+	}
+
+	got, err := m.AppendBinary(nil)
+	require.NoError(t, err)
+	require.Equal(t, buf.Bytes(), got)
+
+	if raceEnabled {
+		return
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = m.AppendBinary(nil)
+	})
+	require.Equal(t, float64(1), allocs)
+}
+
+func BenchmarkMsgDataRowAppendBinary(b *testing.B) {
+	m := wideDataRow()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_, err := m.AppendBinary(nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestMsgDataRowReuse(t *testing.T) {
+	t.Parallel()
+
+	long, err := (&pgwire.MsgDataRow{Columns: [][]byte{[]byte("long value"), nil}}).AppendBinary(nil)
+	require.NoError(t, err)
+
+	short, err := (&pgwire.MsgDataRow{Columns: [][]byte{[]byte("ab"), []byte("c")}}).AppendBinary(nil)
+	require.NoError(t, err)
+
+	var m pgwire.MsgDataRow
+	require.NoError(t, m.UnmarshalBinary(long))
+	require.Equal(t, [][]byte{[]byte("long value"), nil}, m.Columns)
+
+	first := m.Columns[0]
+
+	require.NoError(t, m.UnmarshalBinary(short))
+	require.Equal(t, [][]byte{[]byte("ab"), []byte("c")}, m.Columns)
+	require.Same(t, &first[0], &m.Columns[0][0])
+
+	m.Reset()
+	require.Empty(t, m.Columns)
+
+	require.NoError(t, m.UnmarshalBinary(long))
+	require.Equal(t, [][]byte{[]byte("long value"), nil}, m.Columns)
+}
+
+func TestMsgDataRowReuseError(t *testing.T) {
+	t.Parallel()
+
+	valid, err := (&pgwire.MsgDataRow{Columns: [][]byte{[]byte("ab"), []byte("cd")}}).AppendBinary(nil)
+	require.NoError(t, err)
+
+	// The first column fits, but the second claims more bytes than remain.
+	truncated := pgio.NewBuffer(nil)
+	truncated.AppendByte(byte(pgwire.MessageKindDataRow))
+	truncated.AppendInt32(4 + 2 + 4 + 2 + 4 + 1)
+	truncated.AppendInt16(2)
+	truncated.AppendInt32(2)
+	truncated.AppendByte('x', 'y')
+	truncated.AppendInt32(10)
+	truncated.AppendByte('z')
+
+	var m pgwire.MsgDataRow
+	require.NoError(t, m.UnmarshalBinary(valid))
+
+	err = m.UnmarshalBinary(truncated.Bytes())
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	require.Empty(t, m.Columns)
+
+	trailing := append(bytes.Clone(valid), 0)
+	trailing[4]++
+
+	require.NoError(t, m.UnmarshalBinary(valid))
+
+	err = m.UnmarshalBinaryStrict(trailing)
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	require.Empty(t, m.Columns)
+
+	require.NoError(t, m.UnmarshalBinary(valid))
+	require.Equal(t, [][]byte{[]byte("ab"), []byte("cd")}, m.Columns)
+}
+
+func TestMsgDataRowUnmarshalAllocs(t *testing.T) {
+	b, err := wideDataRow().AppendBinary(nil)
+	require.NoError(t, err)
+
+	var m pgwire.MsgDataRow
+	require.NoError(t, m.UnmarshalBinary(b))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = m.UnmarshalBinary(b)
+	})
+	require.Zero(t, allocs)
+}
+
+func BenchmarkMsgDataRowUnmarshalBinary(b *testing.B) {
+	data, err := wideDataRow().AppendBinary(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var m pgwire.MsgDataRow
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		err := m.UnmarshalBinary(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestMsgErrorResponseError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		fields string
+		values []string
+		want   string
+	}{
+		{
+			name:   "Full",
+			fields: "SCM",
+			values: []string{"ERROR", "42P01", `relation "x" does not exist`},
+			want:   `ERROR: relation "x" does not exist (SQLSTATE 42P01)`,
+		},
+		{
+			name:   "Position",
+			fields: "SCMP",
+			values: []string{"ERROR", "42601", `syntax error at or near "SELEC"`, "1"},
+			want:   `ERROR: syntax error at or near "SELEC" at position 1 (SQLSTATE 42601)`,
+		},
+		{
+			name:   "NoSeverityOrCode",
+			fields: "M",
+			values: []string{"something failed"},
+			want:   "something failed",
+		},
+		{
+			name: "Empty",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &pgwire.MsgErrorResponse{Fields: []byte(tt.fields), Values: tt.values}
+			require.Equal(t, tt.want, m.Error())
+		})
+	}
+}
+
+func TestMsgErrorResponseFields(t *testing.T) {
+	t.Parallel()
+
+	m := (&pgwire.MsgErrorResponse{}).
+		With(pgwire.FieldKindSeverity, "ERROR").
+		With(pgwire.FieldKindCode, "23505").
+		With(pgwire.FieldKindMessage, "duplicate key").
+		With(pgwire.FieldKindDetail, "Key (id)=(1) already exists.").
+		With(pgwire.FieldKindSchema, "public").
+		With(pgwire.FieldKindTable, "t").
+		With(pgwire.FieldKindConstraint, "t_pkey")
+
+	tests := []struct {
+		get  func() (string, bool)
+		want string
+	}{
+		{m.Severity, "ERROR"},
+		{m.Code, "23505"},
+		{m.Message, "duplicate key"},
+		{m.Detail, "Key (id)=(1) already exists."},
+		{m.Schema, "public"},
+		{m.Table, "t"},
+		{m.Constraint, "t_pkey"},
+	}
+
+	for _, tt := range tests {
+		got, ok := tt.get()
+		require.True(t, ok)
+		require.Equal(t, tt.want, got)
+	}
+
+	for _, get := range []func() (string, bool){m.Hint, m.Position, m.Column} {
+		got, ok := get()
+		require.False(t, ok)
+		require.Empty(t, got)
+	}
+
+	where, ok := m.Get(pgwire.FieldKindWhere)
+	require.False(t, ok)
+	require.Empty(t, where)
+
+	// With replaces a field already present instead of adding another.
+	m.With(pgwire.FieldKindSeverity, "FATAL")
+	require.Len(t, m.Fields, 7)
+	require.Len(t, m.Values, 7)
+
+	severity, _ := m.Severity()
+	require.Equal(t, "FATAL", severity)
+}
+
+func TestMsgErrorResponseDuplicateField(t *testing.T) {
+	t.Parallel()
+
+	m := &pgwire.MsgErrorResponse{
+		Fields: []byte("CC"),
+		Values: []string{"42P01", "XX000"},
+	}
+
+	// The first occurrence wins, for Get and With alike.
+	code, ok := m.Code()
+	require.True(t, ok)
+	require.Equal(t, "42P01", code)
+
+	m.With(pgwire.FieldKindCode, "22012")
+	require.Equal(t, []string{"22012", "XX000"}, m.Values)
+}
+
+func TestMsgNoticeResponseFields(t *testing.T) {
+	t.Parallel()
+
+	m := (&pgwire.MsgNoticeResponse{}).
+		With(pgwire.FieldKindSeverity, "WARNING").
+		With(pgwire.FieldKindCode, "01000").
+		With(pgwire.FieldKindMessage, "careful").
+		With(pgwire.FieldKindHint, "look again")
+
+	severity, ok := m.Severity()
+	require.True(t, ok)
+	require.Equal(t, "WARNING", severity)
+
+	code, _ := m.Code()
+	require.Equal(t, "01000", code)
+
+	message, _ := m.Message()
+	require.Equal(t, "careful", message)
+
+	hint, _ := m.Hint()
+	require.Equal(t, "look again", hint)
+
+	_, ok = m.Detail()
+	require.False(t, ok)
+
+	require.Equal(t, pgwire.SeverityLevelWarning, m.SeverityLevel())
+	require.True(t, m.IsWarning())
+}
+
+func TestMsgNoticeResponseSeverityLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		fields string
+		values []string
+		want   pgwire.SeverityLevel
+	}{
+		{"Notice", "S", []string{"NOTICE"}, pgwire.SeverityLevelNotice},
+		{"Debug", "S", []string{"DEBUG2"}, pgwire.SeverityLevelDebug},
+		{"Log", "S", []string{"LOG"}, pgwire.SeverityLevelLog},
+		{"Info", "S", []string{"INFO"}, pgwire.SeverityLevelInfo},
+		// The raw field wins over a localized Severity.
+		{"Localized", "SV", []string{"WARNUNG", "WARNING"}, pgwire.SeverityLevelWarning},
+		{"LocalizedOnly", "S", []string{"WARNUNG"}, pgwire.SeverityLevelUnknown},
+		{"Missing", "", nil, pgwire.SeverityLevelUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &pgwire.MsgNoticeResponse{Fields: []byte(tt.fields), Values: tt.values}
+			require.Equal(t, tt.want, m.SeverityLevel())
+			require.Equal(t, tt.want == pgwire.SeverityLevelWarning, m.IsWarning())
+		})
+	}
+}
+
+func TestPgErrorAs(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgErrorResponse{Fields: []byte("SCM"), Values: []string{"ERROR", "42P01", `relation "x" does not exist`}},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	_, err := pgwire.NewConn(s).SimpleQuery("SELECT * FROM x")
+
+	var pgErr *pgwire.PgError
+	require.ErrorAs(t, err, &pgErr)
+
+	var msg *pgwire.MsgErrorResponse
+	require.ErrorAs(t, err, &msg)
+	require.Equal(t, "42", msg.SQLStateClass())
+	require.Equal(t, pgErr.Error(), msg.Error())
+}
+
+func TestMsgErrorResponseSQLStateClass(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		code       string
+		class      string
+		connection bool
+		integrity  bool
+		rollback   bool
+	}{
+		{code: "08006", class: "08", connection: true},
+		{code: "23505", class: "23", integrity: true},
+		{code: "23503", class: "23", integrity: true},
+		{code: "40001", class: "40", rollback: true},
+		{code: "40P01", class: "40", rollback: true},
+		{code: "42P01", class: "42"},
+		{code: "X", class: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			m := pgwire.MsgErrorResponse{
+				Fields: []byte{byte(pgwire.FieldKindCode)},
+				Values: []string{tt.code},
+			}
+
+			require.Equal(t, tt.class, m.SQLStateClass())
+			require.Equal(t, tt.connection, m.IsConnectionException())
+			require.Equal(t, tt.integrity, m.IsIntegrityConstraintViolation())
+			require.Equal(t, tt.rollback, m.IsTransactionRollback())
+		})
+	}
+
+	t.Run("Absent", func(t *testing.T) {
+		var m pgwire.MsgErrorResponse
+		require.Empty(t, m.SQLStateClass())
+	})
+}
+
+func TestMsgDataRowTrailingBytes(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindDataRow))
+	buf.AppendInt32(15)
+	buf.AppendInt16(1)
+	buf.AppendInt32(3)
+	buf.AppendByte([]byte("abc")...)
+	buf.AppendByte(0xde, 0xad)
+
+	b := buf.Bytes()
+
+	t.Run("Lenient", func(t *testing.T) {
+		t.Parallel()
+
+		var m pgwire.MsgDataRow
+
+		err := m.UnmarshalBinary(b)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("abc")}, m.Columns)
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		t.Parallel()
+
+		var m pgwire.MsgDataRow
+
+		err := m.UnmarshalBinaryStrict(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueOverflow)
+	})
+
+	t.Run("Reader", func(t *testing.T) {
+		t.Parallel()
+
+		r := pgwire.NewMessageReader(bytes.NewReader(b))
+		r.SetStrict(true)
+
+		_, err := r.ReadBackend()
+		require.ErrorIs(t, err, pgio.ErrValueOverflow)
+	})
+}
+
+func TestMsgNegotiateProtocolVersion(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindNegotiateProtocolVersion))
+	buf.AppendInt32(40)
+	buf.AppendInt32(0)
+	buf.AppendInt32(2)
+	buf.AppendString("_pq_.compression")
+	buf.AppendString("_pq_.trace")
+
+	var m pgwire.MsgNegotiateProtocolVersion
+
+	testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+		require.Equal(t, int32(0), m.MinorVersionSupported)
+		require.Equal(t, []string{"_pq_.compression", "_pq_.trace"}, m.UnrecognizedOptions)
+	})
+}
+
+func TestMsgNegotiateProtocolVersionShouldRetry(t *testing.T) {
+	t.Parallel()
+
+	m := pgwire.MsgNegotiateProtocolVersion{
+		UnrecognizedOptions: []string{"_pq_.compression", "_pq_.trace"},
+	}
+
+	require.True(t, m.ShouldRetry(nil))
+	require.True(t, m.ShouldRetry([]string{"_pq_.report_errors"}))
+	require.False(t, m.ShouldRetry([]string{"_pq_.trace"}))
+}
+
+func TestMsgDataRowClone(t *testing.T) {
+	t.Parallel()
+
+	frame := appendMessages(t, &pgwire.MsgDataRow{Columns: [][]byte{[]byte("abc"), nil, {}}})
+
+	var m pgwire.MsgDataRow
+
+	err := m.UnmarshalBinary(frame)
+	require.NoError(t, err)
+
+	clone := m.Clone()
+
+	// Reuse both the frame and the decoded columns.
+	clear(frame)
+	m.Columns[0][0] = 'x'
+
+	require.Equal(t, [][]byte{[]byte("abc"), nil, {}}, clone.Columns)
+}
+
+func TestMsgErrorResponseClone(t *testing.T) {
+	t.Parallel()
+
+	frame := appendMessages(t, &pgwire.MsgErrorResponse{
+		Fields: []byte{byte(pgwire.FieldKindSeverity), byte(pgwire.FieldKindMessage)},
+		Values: []string{"ERROR", "boom"},
+	})
+
+	var m pgwire.MsgErrorResponse
+
+	err := m.UnmarshalBinary(frame)
+	require.NoError(t, err)
+
+	clone := m.Clone()
+
+	// The decoded strings share the frame, the clone does not.
+	copy(frame[6:], "xxxxx")
+	require.Equal(t, "xxxxx", m.Values[0])
+
+	require.Equal(t, []byte{byte(pgwire.FieldKindSeverity), byte(pgwire.FieldKindMessage)}, clone.Fields)
+	require.Equal(t, []string{"ERROR", "boom"}, clone.Values)
+}
+
+func TestBackendLargeCount(t *testing.T) {
+	t.Parallel()
+
+	frame := func(kind pgwire.MessageKind, body ...byte) []byte {
+		buf := pgio.NewBuffer(nil)
+		buf.AppendByte(byte(kind))
+		buf.AppendInt32(int32(4 + len(body)))
+		buf.AppendByte(body...)
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name string
+		m    pgwire.Message
+		b    []byte
+	}{
+		{"CopyInResponse", &pgwire.MsgCopyInResponse{}, frame(pgwire.MessageKindCopyInResponse, 0, 0x7f, 0xff, 0, 0)},
+		{"CopyOutResponse", &pgwire.MsgCopyOutResponse{}, frame(pgwire.MessageKindCopyOutResponse, 0, 0x7f, 0xff, 0, 0)},
+		{"CopyBothResponse", &pgwire.MsgCopyBothResponse{}, frame(pgwire.MessageKindCopyBothResponse, 0, 0x7f, 0xff, 0, 0)},
+		{"DataRow", &pgwire.MsgDataRow{}, frame(pgwire.MessageKindDataRow, 0x7f, 0xff, 0, 0, 0, 0)},
+		{"DataRowNegative", &pgwire.MsgDataRow{}, frame(pgwire.MessageKindDataRow, 0xff, 0xff)},
+		{"ParameterDescription", &pgwire.MsgParameterDescription{}, frame(pgwire.MessageKindParameterDescription, 0x7f, 0xff, 0, 0, 0, 0)},
+		{"RowDescription", &pgwire.MsgRowDescription{}, frame(pgwire.MessageKindRowDescription, 0x7f, 0xff, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.m.UnmarshalBinary(tt.b)
+			require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+			require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+		})
+	}
+}
+
+func TestMsgNotificationResponse(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindNotificationResponse))
+	buf.AppendInt32(19)
+	buf.AppendInt32(42)
+	buf.AppendString("jobs")
+	buf.AppendString("hello")
+
+	var m pgwire.MsgNotificationResponse
+
+	testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+		require.Equal(t, int32(42), m.ProcessID)
+		require.Equal(t, "jobs", m.Channel)
+		require.Equal(t, "hello", m.Payload)
+	})
+}
+
+func TestMsgNotificationResponseEncodesProcessID(t *testing.T) {
+	t.Parallel()
+
+	b, err := (&pgwire.MsgNotificationResponse{ProcessID: 42, Channel: "jobs"}).AppendBinary(nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		byte(pgwire.MessageKindNotificationResponse),
+		0, 0, 0, 14, // length
+		0, 0, 0, 42, // process id
+		'j', 'o', 'b', 's', 0, // channel
+		0, // payload
+	}, b)
+}
+
+func TestNewRowDescription(t *testing.T) {
+	t.Parallel()
+
+	m := pgwire.NewRowDescription([]pgwire.ColumnDescription{
+		{Name: "id", Table: 16384, Column: 1, DataType: 23, Size: 4, Modifier: -1},
+		{Name: "data", Table: 16384, Column: 2, DataType: 17, Size: -1, Modifier: -1, Format: int16(pgwire.FormatKindBinary)},
+	})
+
+	want := &pgwire.MsgRowDescription{
+		Names:     []string{"id", "data"},
+		Tables:    []int32{16384, 16384},
+		Columns:   []int16{1, 2},
+		DataTypes: []int32{23, 17},
+		Sizes:     []int16{4, -1},
+		Modifiers: []int32{-1, -1},
+		Formats:   []int16{int16(pgwire.FormatKindText), int16(pgwire.FormatKindBinary)},
+	}
+	require.Equal(t, want, m)
+
+	got, err := m.AppendBinary(nil)
+	require.NoError(t, err)
+
+	expected, err := want.AppendBinary(nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, got)
+}
+
+func TestMsgRowDescriptionColumnDescriptions(t *testing.T) {
+	t.Parallel()
+
+	columns := []pgwire.ColumnDescription{
+		{Name: "id", Table: 16384, Column: 1, DataType: 23, Size: 4, Modifier: -1},
+		{Name: "name", Table: 16384, Column: 2, DataType: 25, Size: -1, Modifier: -1},
+		{Name: "id", Table: 16385, Column: 1, DataType: 23, Size: 4, Modifier: -1, Format: int16(pgwire.FormatKindBinary)},
+	}
+
+	b, err := pgwire.NewRowDescription(columns).AppendBinary(nil)
+	require.NoError(t, err)
+
+	var m pgwire.MsgRowDescription
+	require.NoError(t, m.UnmarshalBinary(b))
+	require.Equal(t, columns, m.ColumnDescriptions())
+
+	// Rebuilding from the columns encodes to the same bytes.
+	got, err := pgwire.NewRowDescription(m.ColumnDescriptions()).AppendBinary(nil)
+	require.NoError(t, err)
+	require.Equal(t, b, got)
+
+	// Duplicate names resolve to the first column.
+	i, ok := m.Index("id")
+	require.True(t, ok)
+	require.Equal(t, 0, i)
+
+	i, ok = m.Index("name")
+	require.True(t, ok)
+	require.Equal(t, 1, i)
+
+	_, ok = m.Index("missing")
+	require.False(t, ok)
+
+	empty := pgwire.NewRowDescription(nil)
+	require.Empty(t, empty.ColumnDescriptions())
+
+	_, ok = empty.Index("id")
+	require.False(t, ok)
+}
+
+func TestMsgRowDescriptionMismatchedColumns(t *testing.T) {
+	t.Parallel()
+
+	m := pgwire.NewRowDescription([]pgwire.ColumnDescription{{Name: "id"}, {Name: "name"}})
+	m.Formats = m.Formats[:1]
+
+	_, err := m.AppendBinary(nil)
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	require.ErrorIs(t, err, pgio.ErrInvalidValue)
+}
+
+func TestBackendRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		m    pgwire.Backend
+	}{
+		{"AuthenticationOk", &pgwire.MsgAuthenticationOk{}},
+		{"AuthenticationSASL", &pgwire.MsgAuthenticationSASL{Mechanisms: []string{"SCRAM-SHA-256", "SCRAM-SHA-256-PLUS"}}},
+		{"AuthenticationSASLFinal", &pgwire.MsgAuthenticationSASLFinal{Data: []byte("v=abc")}},
+		{"BackendKeyData", &pgwire.MsgBackendKeyData{ProcessID: 7, SecretKey: []byte{1, 2, 3, 4}}},
+		{"BindComplete", &pgwire.MsgBindComplete{}},
+		{"CloseComplete", &pgwire.MsgCloseComplete{}},
+		{"CommandComplete", &pgwire.MsgCommandComplete{Tag: "INSERT 0 1"}},
+		{"CopyData", &pgwire.MsgCopyData{Data: []byte("1\n")}},
+		{"CopyDone", &pgwire.MsgCopyDone{}},
+		{"CopyInResponse", &pgwire.MsgCopyInResponse{Format: 1, Columns: []int16{1, 1}}},
+		{"CopyOutResponse", &pgwire.MsgCopyOutResponse{Columns: []int16{0}}},
+		{"CopyBothResponse", &pgwire.MsgCopyBothResponse{Columns: []int16{}}},
+		{"DataRow", &pgwire.MsgDataRow{Columns: [][]byte{nil, {}, []byte("x")}}},
+		{"EmptyQueryResponse", &pgwire.MsgEmptyQueryResponse{}},
+		{"ErrorResponse", &pgwire.MsgErrorResponse{Fields: []byte{'S', 'C', 'M'}, Values: []string{"ERROR", "XX000", "boom"}}},
+		{"FunctionCallResponseNull", &pgwire.MsgFunctionCallResponse{}},
+		{"FunctionCallResponseEmpty", &pgwire.MsgFunctionCallResponse{Result: []byte{}}},
+		{"FunctionCallResponse", &pgwire.MsgFunctionCallResponse{Result: []byte{0, 0, 0, 1}}},
+		{"NegotiateProtocolVersion", &pgwire.MsgNegotiateProtocolVersion{MinorVersionSupported: 0, UnrecognizedOptions: []string{"_pq_.x"}}},
+		{"NoData", &pgwire.MsgNoData{}},
+		{"NoticeResponse", &pgwire.MsgNoticeResponse{Fields: []byte{'S', 'M'}, Values: []string{"NOTICE", "hi"}}},
+		{"NotificationResponse", &pgwire.MsgNotificationResponse{ProcessID: 9, Channel: "c", Payload: ""}},
+		{"ParameterDescription", &pgwire.MsgParameterDescription{Parameters: []int32{23, 25}}},
+		{"ParameterStatus", &pgwire.MsgParameterStatus{Name: "TimeZone", Value: "UTC"}},
+		{"ParseComplete", &pgwire.MsgParseComplete{}},
+		{"PortalSuspended", &pgwire.MsgPortalSuspended{}},
+		{"ReadyForQuery", &pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindActive)}},
+		{"RowDescription", pgwire.NewRowDescription([]pgwire.ColumnDescription{{Name: "a", DataType: 23, Size: 4, Modifier: -1}})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := tt.m.AppendBinary(nil)
+			require.NoError(t, err)
+
+			m, err := pgwire.NewMessageReader(bytes.NewReader(b)).ReadBackend()
+			require.NoError(t, err)
+			require.Equal(t, tt.m, m)
+
+			again, err := m.AppendBinary(nil)
+			require.NoError(t, err)
+			require.Equal(t, b, again)
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package pgwire
 
 import (
+	"bytes"
 	"gopsql/pgio"
+	"io"
 	"math"
 )
 
@@ -15,6 +17,10 @@ type MsgCopyData struct {
 
 func (x *MsgCopyData) message() {}
 
+func (x *MsgCopyData) Kind() MessageKind {
+	return MessageKindCopyData
+}
+
 func (x *MsgCopyData) frontend() {}
 
 func (x *MsgCopyData) backend() {}
@@ -43,11 +49,22 @@ func (x *MsgCopyData) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
-	x.Data = make([]byte, len(b))
-	copy(x.Data, b)
+	// Reuse the previous buffer; the data is only valid until the next
+	// decode into x.
+	x.Data, _, _ = pgio.ShiftBytesInto(x.Data, b, len(b))
 	return nil
 }
 
+// Reset empties x but keeps its buffer for the next UnmarshalBinary.
+func (x *MsgCopyData) Reset() {
+	x.Data = x.Data[:0]
+}
+
+// Clone returns a deep copy of x.
+func (x *MsgCopyData) Clone() *MsgCopyData {
+	return &MsgCopyData{Data: bytes.Clone(x.Data)}
+}
+
 var _ Message = &MsgCopyDone{}
 var _ Frontend = &MsgCopyDone{}
 var _ Backend = &MsgCopyDone{}
@@ -56,6 +73,10 @@ type MsgCopyDone struct{}
 
 func (x *MsgCopyDone) message() {}
 
+func (x *MsgCopyDone) Kind() MessageKind {
+	return MessageKindCopyDone
+}
+
 func (x *MsgCopyDone) frontend() {}
 
 func (x *MsgCopyDone) backend() {}
@@ -82,3 +103,74 @@ func (x *MsgCopyDone) UnmarshalBinary(b []byte) error {
 	}
 	return nil
 }
+
+var _ Message = &MsgUnknown{}
+var _ Frontend = &MsgUnknown{}
+var _ Backend = &MsgUnknown{}
+
+// MsgUnknown is a message of a kind this package does not know. ReadBackend
+// and ReadFrontend return it instead of failing so a proxy can forward
+// messages added in later protocol versions; its encoding is the original
+// frame. A strict reader rejects unknown kinds instead.
+type MsgUnknown struct {
+	// MessageKind was named Kind before Message gained the Kind method,
+	// which every message type must now provide.
+	MessageKind MessageKind
+	Data        []byte
+}
+
+func (x *MsgUnknown) message() {}
+
+func (x *MsgUnknown) Kind() MessageKind {
+	return x.MessageKind
+}
+
+func (x *MsgUnknown) frontend() {}
+
+func (x *MsgUnknown) backend() {}
+
+func (x *MsgUnknown) AppendBinary(b []byte) ([]byte, error) {
+	sizeData := len(x.Data)
+	length := sizeMessageLength + sizeData
+
+	if length > math.MaxInt32 {
+		return b, invalidFormat(pgio.ErrValueOverflow)
+	}
+
+	size := sizeMessageKind + length
+
+	buf := pgio.NewBuffer(b)
+	buf.Grow(size)
+	buf.AppendByte(byte(x.MessageKind))
+	buf.AppendInt32(int32(length))
+	buf.AppendByte(x.Data...)
+	return buf.Bytes(), nil
+}
+
+func (x *MsgUnknown) UnmarshalBinary(b []byte) error {
+	kind, b, err := pgio.ShiftByte(b)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
+	b, err = shiftLength(b)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
+	x.MessageKind = MessageKind(kind)
+	x.Data = make([]byte, len(b))
+	copy(x.Data, b)
+	return nil
+}
+
+// WriteTo writes the frame to w.
+func (x *MsgUnknown) WriteTo(w io.Writer) (int64, error) {
+	b, err := x.AppendBinary(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(b)
+	return int64(n), err
+}
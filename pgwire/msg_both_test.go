@@ -1,8 +1,10 @@
 package pgwire_test
 
 import (
+	"bytes"
 	"gopsql/pgio"
 	"gopsql/pgwire"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -34,3 +36,136 @@ func TestMsgCopyDone(t *testing.T) {
 
 	testMessage(t, buf.Bytes(), &m, nil)
 }
+
+func TestMsgCopyDataClone(t *testing.T) {
+	t.Parallel()
+
+	m := pgwire.MsgCopyData{Data: []byte("abc")}
+	clone := m.Clone()
+
+	m.Data[0] = 'x'
+	require.Equal(t, []byte("abc"), clone.Data)
+}
+
+func TestMsgCopyDataReuse(t *testing.T) {
+	t.Parallel()
+
+	frame := func(data string) []byte {
+		b, err := (&pgwire.MsgCopyData{Data: []byte(data)}).AppendBinary(nil)
+		require.NoError(t, err)
+		return b
+	}
+
+	var m pgwire.MsgCopyData
+	require.NoError(t, m.UnmarshalBinary(frame("long value")))
+	require.Equal(t, []byte("long value"), m.Data)
+
+	first := m.Data
+
+	require.NoError(t, m.UnmarshalBinary(frame("ab")))
+	require.Equal(t, []byte("ab"), m.Data)
+	require.Same(t, &first[0], &m.Data[0])
+
+	require.NoError(t, m.UnmarshalBinary(frame("")))
+	require.NotNil(t, m.Data)
+	require.Empty(t, m.Data)
+
+	m.Reset()
+	require.Empty(t, m.Data)
+}
+
+func TestCopyFromClient(t *testing.T) {
+	t.Parallel()
+
+	// The frames libpq's PQputCopyData and PQputCopyEnd send for one row.
+	want := []byte{
+		'd', 0, 0, 0, 10, '1', '\t', 'o', 'n', 'e', '\n',
+		'c', 0, 0, 0, 4,
+	}
+
+	s := newScript(t)
+	c := pgwire.NewConn(s)
+
+	err := c.Send(&pgwire.MsgCopyData{Data: []byte("1\tone\n")}, &pgwire.MsgCopyDone{})
+	require.NoError(t, err)
+	require.Equal(t, want, s.out.Bytes())
+
+	r := pgwire.NewMessageReader(bytes.NewReader(want))
+
+	m, err := r.ReadFrontend()
+	require.NoError(t, err)
+	require.Equal(t, &pgwire.MsgCopyData{Data: []byte("1\tone\n")}, m)
+
+	m, err = r.ReadFrontend()
+	require.NoError(t, err)
+	require.Equal(t, &pgwire.MsgCopyDone{}, m)
+}
+
+func TestMsgUnknown(t *testing.T) {
+	t.Parallel()
+
+	frame := []byte{'@', 0, 0, 0, 7, 'a', 'b', 'c'}
+
+	m, err := pgwire.NewMessageReader(bytes.NewReader(frame)).ReadFrontend()
+	require.NoError(t, err)
+	require.Equal(t, &pgwire.MsgUnknown{MessageKind: '@', Data: []byte("abc")}, m)
+
+	var out bytes.Buffer
+
+	n, err := m.(*pgwire.MsgUnknown).WriteTo(&out)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(frame)), n)
+	require.Equal(t, frame, out.Bytes())
+
+	// A client Conn can forward it as is.
+	s := newScript(t)
+
+	err = pgwire.NewConn(s).Send(m)
+	require.NoError(t, err)
+	require.Equal(t, frame, s.out.Bytes())
+
+	t.Run("Backend", func(t *testing.T) {
+		t.Parallel()
+
+		// An unknown kind between known ones does not end the stream.
+		b := append(slices.Clone(frame), appendMessages(t,
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)...)
+		r := pgwire.NewMessageReader(bytes.NewReader(b))
+
+		m, err := r.ReadBackend()
+		require.NoError(t, err)
+		require.Equal(t, &pgwire.MsgUnknown{MessageKind: '@', Data: []byte("abc")}, m)
+
+		got, err := m.AppendBinary(nil)
+		require.NoError(t, err)
+		require.Equal(t, frame, got)
+
+		m, err = r.ReadBackend()
+		require.NoError(t, err)
+		require.IsType(t, &pgwire.MsgReadyForQuery{}, m)
+
+		// A server Conn can forward it as is.
+		s := newScript(t)
+
+		err = pgwire.NewServerConn(s).Send(&pgwire.MsgUnknown{MessageKind: '@', Data: []byte("abc")})
+		require.NoError(t, err)
+		require.Equal(t, frame, s.out.Bytes())
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		t.Parallel()
+
+		r := pgwire.NewMessageReader(bytes.NewReader(frame))
+		r.SetStrict(true)
+
+		_, err := r.ReadFrontend()
+		require.ErrorIs(t, err, pgio.ErrUnknownMessageType)
+
+		r = pgwire.NewMessageReader(bytes.NewReader(frame))
+		r.SetStrict(true)
+
+		_, err = r.ReadBackend()
+		require.ErrorIs(t, err, pgio.ErrUnknownMessageType)
+	})
+}
@@ -1,8 +1,11 @@
 package pgwire
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"gopsql/pgio"
 	"math"
+	"strings"
 )
 
 var _ Message = &MsgBind{}
@@ -18,6 +21,10 @@ type MsgBind struct {
 
 func (x *MsgBind) message() {}
 
+func (x *MsgBind) Kind() MessageKind {
+	return MessageKindBind
+}
+
 func (x *MsgBind) frontend() {}
 
 func (x *MsgBind) AppendBinary(b []byte) ([]byte, error) {
@@ -43,6 +50,10 @@ func (x *MsgBind) AppendBinary(b []byte) ([]byte, error) {
 		return b, invalidFormat(pgio.ErrValueOverflow)
 	}
 
+	if !validFormatCount(paramFmtCodeCount, paramDataCount) {
+		return b, invalidFormat(pgio.ErrInvalidValue)
+	}
+
 	sizeParamData := 0
 
 	for i := range paramDataCount {
@@ -90,9 +101,13 @@ func (x *MsgBind) AppendBinary(b []byte) ([]byte, error) {
 	buf.AppendInt16(int16(paramDataCount))
 
 	for i := range paramDataCount {
-		sizeData := len(x.ParameterData[i])
-		buf.AppendInt32(int32(sizeData))
-		buf.AppendByte(x.ParameterData[i]...)
+		data := x.ParameterData[i]
+		if data == nil {
+			buf.AppendInt32(-1)
+			continue
+		}
+		buf.AppendInt32(int32(len(data)))
+		buf.AppendByte(data...)
 	}
 
 	buf.AppendInt16(int16(colFmtCodeCount))
@@ -125,6 +140,11 @@ func (x *MsgBind) UnmarshalBinary(b []byte) error {
 	if err != nil {
 		return invalidFormat(err)
 	}
+
+	err = checkCount(buf, int(paramFmtCodeCount), 2)
+	if err != nil {
+		return invalidFormat(err)
+	}
 	parameterFormatCodes := make([]FormatKind, paramFmtCodeCount)
 
 	for i := range paramFmtCodeCount {
@@ -139,15 +159,23 @@ func (x *MsgBind) UnmarshalBinary(b []byte) error {
 	if err != nil {
 		return invalidFormat(err)
 	}
+
+	if paramDataCount < 0 {
+		return invalidFormat(pgio.ErrValueUnderflow)
+	}
+
+	if !validFormatCount(int(paramFmtCodeCount), int(paramDataCount)) {
+		return invalidFormat(pgio.ErrInvalidValue)
+	}
+
+	err = checkCount(buf, int(paramDataCount), 4)
+	if err != nil {
+		return invalidFormat(err)
+	}
 	parameterData := make([][]byte, paramDataCount)
 
 	for i := range paramDataCount {
-		dataLen, err := buf.ShiftInt32()
-		if err != nil {
-			return invalidFormat(err)
-		}
-
-		data, err := buf.ShiftBytes(int(dataLen))
+		data, err := shiftNullableBytes(buf)
 		if err != nil {
 			return invalidFormat(err)
 		}
@@ -158,6 +186,11 @@ func (x *MsgBind) UnmarshalBinary(b []byte) error {
 	if err != nil {
 		return invalidFormat(err)
 	}
+
+	err = checkCount(buf, int(colFmtCodeCount), 2)
+	if err != nil {
+		return invalidFormat(err)
+	}
 	columnFormatCodes := make([]FormatKind, colFmtCodeCount)
 
 	for i := range colFmtCodeCount {
@@ -180,6 +213,63 @@ func (x *MsgBind) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// ParameterFormat returns the format of parameter i. No format codes means
+// every parameter is text, and a single code applies to every parameter.
+func (x *MsgBind) ParameterFormat(i int) FormatKind {
+	switch len(x.ParameterFormatCodes) {
+	case 0:
+		return FormatKindText
+	case 1:
+		return x.ParameterFormatCodes[0]
+	}
+	return x.ParameterFormatCodes[i]
+}
+
+// validFormatCount reports whether count format codes can describe n values:
+// none, one shared by all, or one each.
+func validFormatCount(count, n int) bool {
+	return count == 0 || count == 1 || count == n
+}
+
+// BindText builds a Bind whose parameters are all sent in the text format.
+func BindText(portal, statement string, params []string) *MsgBind {
+	formats := make([]FormatKind, len(params))
+	data := make([][]byte, len(params))
+
+	for i, param := range params {
+		formats[i] = FormatKindText
+		data[i] = []byte(param)
+	}
+
+	return &MsgBind{
+		DestinationName:      portal,
+		SourceName:           statement,
+		ParameterFormatCodes: formats,
+		ParameterData:        data,
+	}
+}
+
+// BindTextNullable is like BindText, but a nil parameter is sent as NULL.
+func BindTextNullable(portal, statement string, params []*string) *MsgBind {
+	formats := make([]FormatKind, len(params))
+	data := make([][]byte, len(params))
+
+	for i, param := range params {
+		formats[i] = FormatKindText
+
+		if param != nil {
+			data[i] = []byte(*param)
+		}
+	}
+
+	return &MsgBind{
+		DestinationName:      portal,
+		SourceName:           statement,
+		ParameterFormatCodes: formats,
+		ParameterData:        data,
+	}
+}
+
 var _ Message = &MsgCancelRequest{}
 var _ Frontend = &MsgCancelRequest{}
 
@@ -190,6 +280,10 @@ type MsgCancelRequest struct {
 
 func (x *MsgCancelRequest) message() {}
 
+func (x *MsgCancelRequest) Kind() MessageKind {
+	return MessageKindNone
+}
+
 func (x *MsgCancelRequest) frontend() {}
 
 func (x *MsgCancelRequest) AppendBinary(b []byte) ([]byte, error) {
@@ -239,7 +333,15 @@ func (x *MsgCancelRequest) UnmarshalBinary(b []byte) error {
 
 	processID, err := buf.ShiftInt32()
 	if err != nil {
-		return err
+		return invalidFormat(err)
+	}
+
+	if buf.Len() < 4 {
+		return invalidFormat(pgio.ErrValueUnderflow)
+	}
+
+	if buf.Len() > 256 {
+		return invalidFormat(pgio.ErrValueOverflow)
 	}
 
 	x.ProcessID = processID
@@ -248,6 +350,17 @@ func (x *MsgCancelRequest) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// SecretKeyUint32 returns the secret key as the Int32 of protocol 3.0, or
+// false when it is not exactly 4 bytes long.
+func (x *MsgCancelRequest) SecretKeyUint32() (uint32, bool) {
+	return secretKeyUint32(x.SecretKey)
+}
+
+// Validate checks the secret key length as MsgBackendKeyData.Validate does.
+func (x *MsgCancelRequest) Validate(version ProtocolVersion) error {
+	return validateSecretKey(x.SecretKey, version)
+}
+
 var _ Message = &MsgClose{}
 var _ Frontend = &MsgClose{}
 
@@ -258,11 +371,19 @@ type MsgClose struct {
 
 func (x *MsgClose) message() {}
 
+func (x *MsgClose) Kind() MessageKind {
+	return MessageKindClose
+}
+
 func (x *MsgClose) frontend() {}
 
 func (x *MsgClose) AppendBinary(b []byte) ([]byte, error) {
 	const sizeKind = 1
 
+	if !x.ObjectKind.valid() {
+		return b, invalidFormat(pgio.ErrInvalidValue)
+	}
+
 	sizeName := len(x.ObjectName) + 1 // null terminated string
 
 	length := sizeMessageLength + sizeKind + sizeName
@@ -295,10 +416,18 @@ func (x *MsgClose) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
+	if !ObjectKind(kind).valid() {
+		return invalidFormat(pgio.ErrInvalidValue)
+	}
+
 	name, err := buf.ShiftString()
 	if err != nil {
 		return invalidFormat(err)
 	}
+
+	if buf.Len() > 0 {
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
 	x.ObjectKind = ObjectKind(kind)
 	x.ObjectName = name
 	return nil
@@ -313,9 +442,17 @@ type MsgCopyFail struct {
 
 func (x *MsgCopyFail) message() {}
 
+func (x *MsgCopyFail) Kind() MessageKind {
+	return MessageKindCopyFail
+}
+
 func (x *MsgCopyFail) frontend() {}
 
 func (x *MsgCopyFail) AppendBinary(b []byte) ([]byte, error) {
+	if strings.IndexByte(x.Message, 0) >= 0 {
+		return b, invalidFormat(pgio.ErrEmbeddedNull)
+	}
+
 	sizeMessage := len(x.Message) + 1 // null terminated string
 	length := sizeMessageLength + sizeMessage
 
@@ -361,11 +498,19 @@ type MsgDescribe struct {
 
 func (x *MsgDescribe) message() {}
 
+func (x *MsgDescribe) Kind() MessageKind {
+	return MessageKindDescribe
+}
+
 func (x *MsgDescribe) frontend() {}
 
 func (x *MsgDescribe) AppendBinary(b []byte) ([]byte, error) {
 	const sizeKind = 1
 
+	if !x.ObjectKind.valid() {
+		return b, invalidFormat(pgio.ErrInvalidValue)
+	}
+
 	length := sizeMessageLength +
 		sizeKind +
 		len(x.ObjectName) + 1 // null terminated string
@@ -398,11 +543,19 @@ func (x *MsgDescribe) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
+	if !ObjectKind(kind).valid() {
+		return invalidFormat(pgio.ErrInvalidValue)
+	}
+
 	name, err := buf.ShiftString()
 	if err != nil {
 		return invalidFormat(err)
 	}
 
+	if buf.Len() > 0 {
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
+
 	x.ObjectKind = ObjectKind(kind)
 	x.ObjectName = name
 	return nil
@@ -411,6 +564,9 @@ func (x *MsgDescribe) UnmarshalBinary(b []byte) error {
 var _ Message = &MsgExecute{}
 var _ Frontend = &MsgExecute{}
 
+// MsgExecute runs a bound portal. An empty PortalName selects the unnamed
+// portal. A RowLimit of zero returns all rows; otherwise the server stops
+// after RowLimit rows with PortalSuspended and a later Execute resumes.
 type MsgExecute struct {
 	PortalName string
 	RowLimit   int32
@@ -418,6 +574,10 @@ type MsgExecute struct {
 
 func (x *MsgExecute) message() {}
 
+func (x *MsgExecute) Kind() MessageKind {
+	return MessageKindExecute
+}
+
 func (x *MsgExecute) frontend() {}
 
 func (x *MsgExecute) AppendBinary(b []byte) ([]byte, error) {
@@ -460,6 +620,10 @@ func (x *MsgExecute) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
+	if buf.Len() > 0 {
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
+
 	x.PortalName = portal
 	x.RowLimit = limit
 	return nil
@@ -472,6 +636,10 @@ type MsgFlush struct{}
 
 func (x *MsgFlush) message() {}
 
+func (x *MsgFlush) Kind() MessageKind {
+	return MessageKindFlush
+}
+
 func (x *MsgFlush) frontend() {}
 
 func (x *MsgFlush) AppendBinary(b []byte) ([]byte, error) {
@@ -515,6 +683,10 @@ type MsgFunctionCall struct {
 
 func (x *MsgFunctionCall) message() {}
 
+func (x *MsgFunctionCall) Kind() MessageKind {
+	return MessageKindFunctionCall
+}
+
 func (x *MsgFunctionCall) frontend() {}
 
 func (x *MsgFunctionCall) AppendBinary(b []byte) ([]byte, error) {
@@ -537,6 +709,10 @@ func (x *MsgFunctionCall) AppendBinary(b []byte) ([]byte, error) {
 		return b, invalidFormat(pgio.ErrValueOverflow)
 	}
 
+	if !validFormatCount(countFormats, countArguments) {
+		return b, invalidFormat(pgio.ErrInvalidValue)
+	}
+
 	sizeFormats := countFormats * sizeFormat
 	sizeArguments := 0
 
@@ -577,8 +753,11 @@ func (x *MsgFunctionCall) AppendBinary(b []byte) ([]byte, error) {
 
 	for i := range countArguments {
 		value := x.ArgumentValues[i]
-		length := len(value)
-		buf.AppendInt32(int32(length))
+		if value == nil {
+			buf.AppendInt32(-1)
+			continue
+		}
+		buf.AppendInt32(int32(len(value)))
 		buf.AppendByte(value...)
 	}
 	buf.AppendInt16(int16(x.ResultFormat))
@@ -603,6 +782,11 @@ func (x *MsgFunctionCall) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
+	err = checkCount(buf, int(countFormats), 2)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
 	formats := make([]FormatKind, 0, countFormats)
 	for range countFormats {
 		format, err := buf.ShiftInt16()
@@ -617,13 +801,18 @@ func (x *MsgFunctionCall) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
+	if !validFormatCount(len(formats), int(countArguments)) {
+		return invalidFormat(pgio.ErrInvalidValue)
+	}
+
+	err = checkCount(buf, int(countArguments), 4)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
 	arguments := make([][]byte, 0, countArguments)
 	for range countArguments {
-		length, err := buf.ShiftInt32()
-		if err != nil {
-			return invalidFormat(err)
-		}
-		value, err := buf.ShiftBytes(int(length))
+		value, err := shiftNullableBytes(buf)
 		if err != nil {
 			return invalidFormat(err)
 		}
@@ -653,6 +842,10 @@ type MsgGSSENCRequest struct{}
 
 func (x *MsgGSSENCRequest) message() {}
 
+func (x *MsgGSSENCRequest) Kind() MessageKind {
+	return MessageKindNone
+}
+
 func (x *MsgGSSENCRequest) frontend() {}
 
 func (x *MsgGSSENCRequest) AppendBinary(b []byte) ([]byte, error) {
@@ -696,6 +889,10 @@ type MsgGSSResponse struct {
 
 func (x *MsgGSSResponse) message() {}
 
+func (x *MsgGSSResponse) Kind() MessageKind {
+	return MessageKindGSSResponse
+}
+
 func (x *MsgGSSResponse) frontend() {}
 
 func (x *MsgGSSResponse) AppendBinary(b []byte) ([]byte, error) {
@@ -736,6 +933,10 @@ type MsgParse struct {
 
 func (x *MsgParse) message() {}
 
+func (x *MsgParse) Kind() MessageKind {
+	return MessageKindParse
+}
+
 func (x *MsgParse) frontend() {}
 
 func (x *MsgParse) AppendBinary(b []byte) ([]byte, error) {
@@ -747,7 +948,8 @@ func (x *MsgParse) AppendBinary(b []byte) ([]byte, error) {
 
 	countParameterDataTypes := len(x.ParameterDataTypes)
 
-	if countParameterDataTypes > math.MaxInt16 {
+	// The server reads the count as unsigned.
+	if countParameterDataTypes > math.MaxUint16 {
 		return b, invalidFormat(pgio.ErrValueOverflow)
 	}
 
@@ -770,7 +972,7 @@ func (x *MsgParse) AppendBinary(b []byte) ([]byte, error) {
 	buf.AppendInt32(int32(length))
 	buf.AppendString(x.DestinationStatementName)
 	buf.AppendString(x.Query)
-	buf.AppendInt16(int16(countParameterDataTypes))
+	buf.AppendInt16(int16(uint16(countParameterDataTypes)))
 
 	for i := range countParameterDataTypes {
 		buf.AppendInt32(x.ParameterDataTypes[i])
@@ -796,7 +998,14 @@ func (x *MsgParse) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
-	countParameterDataTypes, err := buf.ShiftInt16()
+	count, err := buf.ShiftInt16()
+	if err != nil {
+		return invalidFormat(err)
+	}
+
+	countParameterDataTypes := int(uint16(count))
+
+	err = checkCount(buf, countParameterDataTypes, 4)
 	if err != nil {
 		return invalidFormat(err)
 	}
@@ -811,12 +1020,32 @@ func (x *MsgParse) UnmarshalBinary(b []byte) error {
 		parameterDataTypes = append(parameterDataTypes, parameterDataType)
 	}
 
+	if buf.Len() > 0 {
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
+
 	x.DestinationStatementName = destinationStatementName
 	x.Query = query
 	x.ParameterDataTypes = parameterDataTypes
 	return nil
 }
 
+// NewParse builds a Parse that leaves the server to infer every parameter
+// type.
+func NewParse(name, query string) *MsgParse {
+	return &MsgParse{DestinationStatementName: name, Query: query}
+}
+
+// NewParseTyped builds a Parse that specifies parameter types by OID. An OID
+// of zero leaves that parameter's type to the server.
+func NewParseTyped(name, query string, oids []int32) *MsgParse {
+	return &MsgParse{
+		DestinationStatementName: name,
+		Query:                    query,
+		ParameterDataTypes:       oids,
+	}
+}
+
 var _ Message = &MsgPasswordMessage{}
 var _ Frontend = &MsgPasswordMessage{}
 
@@ -826,6 +1055,10 @@ type MsgPasswordMessage struct {
 
 func (x *MsgPasswordMessage) message() {}
 
+func (x *MsgPasswordMessage) Kind() MessageKind {
+	return MessageKindPasswordMessage
+}
+
 func (x *MsgPasswordMessage) frontend() {}
 
 func (x *MsgPasswordMessage) AppendBinary(b []byte) ([]byte, error) {
@@ -867,6 +1100,24 @@ func (x *MsgPasswordMessage) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// MD5Password builds the response to an AuthenticationMD5Password request:
+// "md5" followed by the hex digest of md5(password + user) and the salt.
+func MD5Password(user, password string, salt [4]byte) *MsgPasswordMessage {
+	inner := md5.Sum([]byte(password + user))
+
+	h := md5.New()
+	h.Write([]byte(hex.EncodeToString(inner[:])))
+	h.Write(salt[:])
+
+	return &MsgPasswordMessage{Password: "md5" + hex.EncodeToString(h.Sum(nil))}
+}
+
+// CleartextPassword builds the response to an
+// AuthenticationCleartextPassword request, which carries password as is.
+func CleartextPassword(password string) *MsgPasswordMessage {
+	return &MsgPasswordMessage{Password: password}
+}
+
 var _ Message = &MsgQuery{}
 var _ Frontend = &MsgQuery{}
 
@@ -876,13 +1127,26 @@ type MsgQuery struct {
 
 func (x *MsgQuery) message() {}
 
+func (x *MsgQuery) Kind() MessageKind {
+	return MessageKindQuery
+}
+
 func (x *MsgQuery) frontend() {}
 
 func (x *MsgQuery) AppendBinary(b []byte) ([]byte, error) {
-	sizeQuery := len(x.Value)
+	// The server would stop reading the query at the first null byte.
+	if strings.IndexByte(x.Value, 0) >= 0 {
+		return b, invalidFormat(pgio.ErrEmbeddedNull)
+	}
+
+	sizeQuery := len(x.Value) + 1 // null terminated string
 
 	length := sizeMessageLength + sizeQuery
 
+	if length > math.MaxInt32 {
+		return b, invalidFormat(pgio.ErrValueOverflow)
+	}
+
 	size := sizeMessageKind + length
 
 	buf := pgio.NewBuffer(b)
@@ -923,6 +1187,10 @@ type MsgSASLInitialResponse struct {
 
 func (x *MsgSASLInitialResponse) message() {}
 
+func (x *MsgSASLInitialResponse) Kind() MessageKind {
+	return MessageKindSASLInitialResponse
+}
+
 func (x *MsgSASLInitialResponse) frontend() {}
 
 func (x *MsgSASLInitialResponse) AppendBinary(b []byte) ([]byte, error) {
@@ -952,8 +1220,13 @@ func (x *MsgSASLInitialResponse) AppendBinary(b []byte) ([]byte, error) {
 	buf.AppendByte(byte(MessageKindSASLInitialResponse))
 	buf.AppendInt32(int32(length))
 	buf.AppendString(x.Name)
-	buf.AppendInt32(int32(sizeResponse))
-	buf.AppendByte(x.Response...)
+
+	if x.Response == nil {
+		buf.AppendInt32(-1)
+	} else {
+		buf.AppendInt32(int32(sizeResponse))
+		buf.AppendByte(x.Response...)
+	}
 	return buf.Bytes(), nil
 }
 
@@ -970,12 +1243,7 @@ func (x *MsgSASLInitialResponse) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
-	sizeResponse, err := buf.ShiftInt32()
-	if err != nil {
-		return invalidFormat(err)
-	}
-
-	response, err := buf.ShiftBytes(int(sizeResponse))
+	response, err := shiftNullableBytes(buf)
 	if err != nil {
 		return invalidFormat(err)
 	}
@@ -992,12 +1260,19 @@ func (x *MsgSASLInitialResponse) UnmarshalBinary(b []byte) error {
 var _ Message = &MsgSASLResponse{}
 var _ Frontend = &MsgSASLResponse{}
 
+// MsgSASLResponse carries mechanism specific data, such as a SCRAM
+// client-final-message. Data is byte for byte the rest of the message body;
+// unlike SASLInitialResponse it has no length prefix of its own.
 type MsgSASLResponse struct {
 	Data []byte
 }
 
 func (x *MsgSASLResponse) message() {}
 
+func (x *MsgSASLResponse) Kind() MessageKind {
+	return MessageKindSASLResponse
+}
+
 func (x *MsgSASLResponse) frontend() {}
 
 func (x *MsgSASLResponse) AppendBinary(b []byte) ([]byte, error) {
@@ -1038,6 +1313,10 @@ type MsgSSLRequest struct{}
 
 func (x *MsgSSLRequest) message() {}
 
+func (x *MsgSSLRequest) Kind() MessageKind {
+	return MessageKindNone
+}
+
 func (x *MsgSSLRequest) frontend() {}
 
 func (x *MsgSSLRequest) AppendBinary(b []byte) ([]byte, error) {
@@ -1087,23 +1366,47 @@ func (x ProtocolVersion) Minor() int32 {
 var _ Message = &MsgStartupMessage{}
 var _ Frontend = &MsgStartupMessage{}
 
+// StartupParameter is one name and value pair of a StartupMessage.
+type StartupParameter struct {
+	Name  string
+	Value string
+}
+
 type MsgStartupMessage struct {
 	ProtocolVersion ProtocolVersion
-	Parameters      map[string]string
+	// Parameters are encoded in order, which keeps the packet deterministic.
+	Parameters []StartupParameter
 }
 
 func (x *MsgStartupMessage) message() {}
 
+func (x *MsgStartupMessage) Kind() MessageKind {
+	return MessageKindNone
+}
+
 func (x *MsgStartupMessage) frontend() {}
 
+// Get returns the value of the named parameter.
+func (x *MsgStartupMessage) Get(name string) (string, bool) {
+	for _, p := range x.Parameters {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
 func (x *MsgStartupMessage) AppendBinary(b []byte) ([]byte, error) {
 	const sizeProtocolVersion = 4
 
 	var sizeParameters int
 
-	for key, value := range x.Parameters {
-		sizeParameters += (len(key) + 1)   // null terminated string
-		sizeParameters += (len(value) + 1) // null terminated string
+	for _, p := range x.Parameters {
+		if len(p.Name) == 0 {
+			return b, invalidFormat(pgio.ErrValueUnderflow)
+		}
+		sizeParameters += (len(p.Name) + 1)  // null terminated string
+		sizeParameters += (len(p.Value) + 1) // null terminated string
 	}
 	sizeParameters += 1 // null terminated list
 
@@ -1121,20 +1424,26 @@ func (x *MsgStartupMessage) AppendBinary(b []byte) ([]byte, error) {
 	buf.AppendInt32(int32(length))
 	buf.AppendInt32(int32(x.ProtocolVersion))
 
-	for key, value := range x.Parameters {
-		buf.AppendString(key)
-		buf.AppendString(value)
+	for _, p := range x.Parameters {
+		buf.AppendString(p.Name)
+		buf.AppendString(p.Value)
 	}
 	buf.AppendByte(0)
 	return buf.Bytes(), nil
 }
 
 func (x *MsgStartupMessage) UnmarshalBinary(b []byte) error {
+	const sizeProtocolVersion = 4
+
 	b, err := shiftLength(b)
 	if err != nil {
 		return invalidFormat(err)
 	}
 
+	if len(b) < sizeProtocolVersion {
+		return invalidFormat(pgio.ErrValueUnderflow)
+	}
+
 	buf := pgio.NewBuffer(b)
 
 	protocolVersion, err := buf.ShiftInt32()
@@ -1142,27 +1451,103 @@ func (x *MsgStartupMessage) UnmarshalBinary(b []byte) error {
 		return invalidFormat(err)
 	}
 
-	parameters := make(map[string]string)
+	var parameters []StartupParameter
 
-	key, err := buf.ShiftString()
+	name, err := buf.ShiftString()
 	if err != nil {
 		return invalidFormat(err)
 	}
 
-	for len(key) > 0 {
+	for len(name) > 0 {
 		value, err := buf.ShiftString()
 		if err != nil {
 			return invalidFormat(err)
 		}
-		parameters[key] = value
+		parameters = append(parameters, StartupParameter{Name: name, Value: value})
 
-		key, err = buf.ShiftString()
+		name, err = buf.ShiftString()
 		if err != nil {
 			return invalidFormat(err)
 		}
 	}
 
+	if buf.Len() > 0 {
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
+
 	x.ProtocolVersion = ProtocolVersion(protocolVersion)
 	x.Parameters = parameters
 	return nil
 }
+
+var _ Message = &MsgSync{}
+var _ Frontend = &MsgSync{}
+
+type MsgSync struct{}
+
+func (x *MsgSync) message() {}
+
+func (x *MsgSync) Kind() MessageKind {
+	return MessageKindSync
+}
+
+func (x *MsgSync) frontend() {}
+
+func (x *MsgSync) AppendBinary(b []byte) ([]byte, error) {
+	const length = sizeMessageLength
+	const size = sizeMessageKind + length
+
+	buf := pgio.NewBuffer(b)
+	buf.Grow(size)
+	buf.AppendByte(byte(MessageKindSync))
+	buf.AppendInt32(int32(length))
+	return buf.Bytes(), nil
+}
+
+func (x *MsgSync) UnmarshalBinary(b []byte) error {
+	b, err := shiftHeader(MessageKindSync, b)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
+	if len(b) > 0 {
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
+	return nil
+}
+
+var _ Message = &MsgTerminate{}
+var _ Frontend = &MsgTerminate{}
+
+type MsgTerminate struct{}
+
+func (x *MsgTerminate) message() {}
+
+func (x *MsgTerminate) Kind() MessageKind {
+	return MessageKindTerminate
+}
+
+func (x *MsgTerminate) frontend() {}
+
+func (x *MsgTerminate) AppendBinary(b []byte) ([]byte, error) {
+	const length = sizeMessageLength
+	const size = sizeMessageKind + length
+
+	buf := pgio.NewBuffer(b)
+	buf.Grow(size)
+	buf.AppendByte(byte(MessageKindTerminate))
+	buf.AppendInt32(int32(length))
+	return buf.Bytes(), nil
+}
+
+func (x *MsgTerminate) UnmarshalBinary(b []byte) error {
+	b, err := shiftHeader(MessageKindTerminate, b)
+	if err != nil {
+		return invalidFormat(err)
+	}
+
+	if len(b) > 0 {
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
+	return nil
+}
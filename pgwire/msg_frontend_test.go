@@ -1,8 +1,10 @@
 package pgwire_test
 
 import (
+	"bytes"
 	"gopsql/pgio"
 	"gopsql/pgwire"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -63,6 +65,124 @@ func TestMsgBind(t *testing.T) {
 	})
 }
 
+func appendBindFrame(body []byte) []byte {
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindBind))
+	buf.AppendInt32(int32(4 + len(body)))
+	buf.AppendByte(body...)
+	return buf.Bytes()
+}
+
+func TestMsgBindParameterFormat(t *testing.T) {
+	t.Parallel()
+
+	params := [][]byte{[]byte("1"), nil}
+
+	tests := map[string]struct {
+		codes []pgwire.FormatKind
+		want  []pgwire.FormatKind
+	}{
+		"None": {
+			codes: nil,
+			want:  []pgwire.FormatKind{pgwire.FormatKindText, pgwire.FormatKindText},
+		},
+		"Shared": {
+			codes: []pgwire.FormatKind{pgwire.FormatKindBinary},
+			want:  []pgwire.FormatKind{pgwire.FormatKindBinary, pgwire.FormatKindBinary},
+		},
+		"Each": {
+			codes: []pgwire.FormatKind{pgwire.FormatKindBinary, pgwire.FormatKindText},
+			want:  []pgwire.FormatKind{pgwire.FormatKindBinary, pgwire.FormatKindText},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := (&pgwire.MsgBind{ParameterFormatCodes: tt.codes, ParameterData: params}).AppendBinary(nil)
+			require.NoError(t, err)
+
+			var m pgwire.MsgBind
+
+			err = m.UnmarshalBinary(b)
+			require.NoError(t, err)
+			require.Equal(t, params, m.ParameterData)
+
+			for i, want := range tt.want {
+				require.Equal(t, want, m.ParameterFormat(i))
+			}
+		})
+	}
+
+	t.Run("Mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := (&pgwire.MsgBind{
+			ParameterFormatCodes: []pgwire.FormatKind{pgwire.FormatKindText, pgwire.FormatKindText},
+			ParameterData:        [][]byte{nil, nil, nil},
+		}).AppendBinary(nil)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrInvalidValue)
+
+		body := pgio.NewBuffer(nil)
+		body.AppendString("", "")
+		body.AppendInt16(2, 0, 0)
+		body.AppendInt16(3)
+		body.AppendInt32(-1, -1, -1)
+		body.AppendInt16(0)
+
+		err = (&pgwire.MsgBind{}).UnmarshalBinary(appendBindFrame(body.Bytes()))
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrInvalidValue)
+	})
+}
+
+func TestMsgBindInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]func(*pgio.Buffer){
+		"LengthPastBody": func(buf *pgio.Buffer) {
+			buf.AppendInt16(0)
+			buf.AppendInt16(1)
+			buf.AppendInt32(100)
+			buf.AppendByte([]byte("abc")...)
+		},
+		"NegativeParameterCount": func(buf *pgio.Buffer) {
+			buf.AppendInt16(0)
+			buf.AppendInt16(-1)
+			buf.AppendInt16(0)
+		},
+		"ParameterCountPastBody": func(buf *pgio.Buffer) {
+			buf.AppendInt16(0)
+			buf.AppendInt16(math.MaxInt16)
+			buf.AppendInt16(0)
+		},
+		"NegativeFormatCount": func(buf *pgio.Buffer) {
+			buf.AppendInt16(-1)
+		},
+		"NegativeColumnFormatCount": func(buf *pgio.Buffer) {
+			buf.AppendInt16(0)
+			buf.AppendInt16(0)
+			buf.AppendInt16(-1)
+		},
+	}
+
+	for name, fn := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			body := pgio.NewBuffer(nil)
+			body.AppendString("", "")
+			fn(body)
+
+			err := (&pgwire.MsgBind{}).UnmarshalBinary(appendBindFrame(body.Bytes()))
+			require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+			require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+		})
+	}
+}
+
 func TestMsgCancelRequest(t *testing.T) {
 	t.Parallel()
 
@@ -80,6 +200,34 @@ func TestMsgCancelRequest(t *testing.T) {
 	})
 }
 
+func TestMsgCancelRequestSecretKey(t *testing.T) {
+	t.Parallel()
+
+	v3_0 := pgwire.ProtocolVersion(pgwire.ProtocolVersion3_0)
+	v3_2 := pgwire.ProtocolVersion(pgwire.ProtocolVersion3_2)
+
+	m := pgwire.MsgCancelRequest{ProcessID: 7, SecretKey: []byte{0, 0, 0, 9}}
+
+	key, ok := m.SecretKeyUint32()
+	require.True(t, ok)
+	require.Equal(t, uint32(9), key)
+	require.NoError(t, m.Validate(v3_0))
+
+	m.SecretKey = make([]byte, 32)
+	require.ErrorIs(t, m.Validate(v3_0), pgwire.ErrInvalidFormat)
+	require.NoError(t, m.Validate(v3_2))
+
+	m.SecretKey = []byte{1, 2, 3}
+	require.ErrorIs(t, m.Validate(v3_2), pgwire.ErrInvalidFormat)
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendInt32(15)
+	buf.AppendInt32(pgwire.CodeCancelRequest)
+	buf.AppendInt32(7)
+	buf.AppendByte(1, 2, 3)
+	require.ErrorIs(t, m.UnmarshalBinary(buf.Bytes()), pgwire.ErrInvalidFormat)
+}
+
 func TestMsgClose(t *testing.T) {
 	t.Parallel()
 
@@ -95,6 +243,48 @@ func TestMsgClose(t *testing.T) {
 		require.Equal(t, pgwire.ObjectKindPortal, m.ObjectKind)
 		require.Equal(t, "hello world", m.ObjectName)
 	})
+
+	t.Run("Unnamed", func(t *testing.T) {
+		b, err := (&pgwire.MsgClose{ObjectKind: pgwire.ObjectKindStatement}).AppendBinary(nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte{byte(pgwire.MessageKindClose), 0, 0, 0, 6, 'S', 0}, b)
+
+		var m pgwire.MsgClose
+
+		err = m.UnmarshalBinary(b)
+		require.NoError(t, err)
+		require.Equal(t, pgwire.ObjectKindStatement, m.ObjectKind)
+		require.Empty(t, m.ObjectName)
+	})
+
+	t.Run("InvalidKind", func(t *testing.T) {
+		_, err := (&pgwire.MsgClose{ObjectKind: 'X'}).AppendBinary(nil)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrInvalidValue)
+
+		b := bytes.Clone(buf.Bytes())
+		b[5] = 'X'
+
+		err = (&pgwire.MsgClose{}).UnmarshalBinary(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrInvalidValue)
+	})
+
+	t.Run("MissingTerminator", func(t *testing.T) {
+		b := []byte{byte(pgwire.MessageKindClose), 0, 0, 0, 7, 'P', 'a', 'b'}
+
+		err := (&pgwire.MsgClose{}).UnmarshalBinary(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+	})
+
+	t.Run("TrailingBytes", func(t *testing.T) {
+		b := []byte{byte(pgwire.MessageKindClose), 0, 0, 0, 7, 'P', 0, 0}
+
+		err := (&pgwire.MsgClose{}).UnmarshalBinary(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueOverflow)
+	})
 }
 
 func TestMsgCopyFail(t *testing.T) {
@@ -110,6 +300,30 @@ func TestMsgCopyFail(t *testing.T) {
 	testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
 		require.Equal(t, "hello world", m.Message)
 	})
+
+	t.Run("Empty", func(t *testing.T) {
+		b := []byte{byte(pgwire.MessageKindCopyFail), 0, 0, 0, 5, 0}
+
+		var m pgwire.MsgCopyFail
+
+		testMessage(t, b, &m, func(t *testing.T) {
+			require.Empty(t, m.Message)
+		})
+	})
+
+	t.Run("MissingTerminator", func(t *testing.T) {
+		b := []byte{byte(pgwire.MessageKindCopyFail), 0, 0, 0, 6, 'a', 'b'}
+
+		err := (&pgwire.MsgCopyFail{}).UnmarshalBinary(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+	})
+
+	t.Run("EmbeddedNull", func(t *testing.T) {
+		_, err := (&pgwire.MsgCopyFail{Message: "a\x00b"}).AppendBinary(nil)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrEmbeddedNull)
+	})
 }
 
 func TestMsgDescribe(t *testing.T) {
@@ -127,6 +341,31 @@ func TestMsgDescribe(t *testing.T) {
 		require.Equal(t, pgwire.ObjectKindPortal, m.ObjectKind)
 		require.Equal(t, "hello world", m.ObjectName)
 	})
+
+	t.Run("Statement", func(t *testing.T) {
+		b, err := (&pgwire.MsgDescribe{ObjectKind: pgwire.ObjectKindStatement}).AppendBinary(nil)
+		require.NoError(t, err)
+
+		var m pgwire.MsgDescribe
+
+		err = m.UnmarshalBinary(b)
+		require.NoError(t, err)
+		require.Equal(t, pgwire.ObjectKindStatement, m.ObjectKind)
+		require.Empty(t, m.ObjectName)
+	})
+
+	t.Run("InvalidKind", func(t *testing.T) {
+		_, err := (&pgwire.MsgDescribe{ObjectKind: 'X'}).AppendBinary(nil)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrInvalidValue)
+
+		b := bytes.Clone(buf.Bytes())
+		b[5] = 'X'
+
+		err = (&pgwire.MsgDescribe{}).UnmarshalBinary(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrInvalidValue)
+	})
 }
 
 func TestMsgExecute(t *testing.T) {
@@ -146,6 +385,31 @@ func TestMsgExecute(t *testing.T) {
 	})
 }
 
+func TestMsgExecuteUnnamed(t *testing.T) {
+	t.Parallel()
+
+	b := []byte{byte(pgwire.MessageKindExecute), 0, 0, 0, 9, 0, 0, 0, 0, 0}
+
+	var m pgwire.MsgExecute
+
+	testMessage(t, b, &m, func(t *testing.T) {
+		require.Empty(t, m.PortalName)
+		require.Zero(t, m.RowLimit)
+	})
+}
+
+func TestMsgExecuteTrailingBytes(t *testing.T) {
+	t.Parallel()
+
+	b := []byte{byte(pgwire.MessageKindExecute), 0, 0, 0, 10, 0, 0, 0, 0, 0, 0}
+
+	var m pgwire.MsgExecute
+
+	err := m.UnmarshalBinary(b)
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	require.ErrorIs(t, err, pgio.ErrValueOverflow)
+}
+
 func TestMsgFlush(t *testing.T) {
 	t.Parallel()
 
@@ -158,6 +422,24 @@ func TestMsgFlush(t *testing.T) {
 	testMessage(t, buf.Bytes(), &m, nil)
 }
 
+func TestMsgGSSENCRequest(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendInt32(8)
+	buf.AppendInt32(80877104)
+
+	testMessage(t, buf.Bytes(), &pgwire.MsgGSSENCRequest{}, nil)
+
+	t.Run("SSLRequest", func(t *testing.T) {
+		b := appendMessages(t, &pgwire.MsgSSLRequest{})
+
+		err := (&pgwire.MsgGSSENCRequest{}).UnmarshalBinary(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrUnknownCode)
+	})
+}
+
 func TestMsgFunctionCall(t *testing.T) {
 	t.Parallel()
 
@@ -191,3 +473,588 @@ func TestMsgFunctionCall(t *testing.T) {
 		require.Equal(t, pgwire.FormatKindBinary, m.ResultFormat)
 	})
 }
+
+func TestMsgFunctionCallArguments(t *testing.T) {
+	t.Parallel()
+
+	t.Run("None", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := (&pgwire.MsgFunctionCall{ObjectID: 7}).AppendBinary(nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte{
+			byte(pgwire.MessageKindFunctionCall), 0, 0, 0, 14,
+			0, 0, 0, 7, // object id
+			0, 0, // format count
+			0, 0, // argument count
+			0, 0, // result format
+		}, b)
+
+		var m pgwire.MsgFunctionCall
+
+		err = m.UnmarshalBinary(b)
+		require.NoError(t, err)
+		require.Empty(t, m.ArgumentFormats)
+		require.Empty(t, m.ArgumentValues)
+	})
+
+	t.Run("SingleBinary", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := (&pgwire.MsgFunctionCall{
+			ObjectID:        7,
+			ArgumentFormats: []pgwire.FormatKind{pgwire.FormatKindBinary},
+			ArgumentValues:  [][]byte{{0, 0, 0, 42}},
+			ResultFormat:    pgwire.FormatKindBinary,
+		}).AppendBinary(nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte{
+			byte(pgwire.MessageKindFunctionCall), 0, 0, 0, 24,
+			0, 0, 0, 7, // object id
+			0, 1, // format count
+			0, 1, // binary
+			0, 1, // argument count
+			0, 0, 0, 4, 0, 0, 0, 42, // argument
+			0, 1, // result format
+		}, b)
+	})
+
+	t.Run("Null", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := (&pgwire.MsgFunctionCall{ObjectID: 7, ArgumentValues: [][]byte{nil, {}}}).AppendBinary(nil)
+		require.NoError(t, err)
+
+		var m pgwire.MsgFunctionCall
+
+		err = m.UnmarshalBinary(b)
+		require.NoError(t, err)
+		require.Nil(t, m.ArgumentValues[0])
+		require.NotNil(t, m.ArgumentValues[1])
+		require.Empty(t, m.ArgumentValues[1])
+	})
+
+	t.Run("ArgumentLength", func(t *testing.T) {
+		t.Parallel()
+
+		b := []byte{byte(pgwire.MessageKindFunctionCall), 0, 0, 0, 20, 0, 0, 0, 7, 0, 0, 0, 1}
+		b = append(b, 0, 0, 0, 9)
+		b = append(b, 'a', 'b', 0, 0)
+
+		err := (&pgwire.MsgFunctionCall{}).UnmarshalBinary(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+	})
+
+	t.Run("FormatCountMismatch", func(t *testing.T) {
+		t.Parallel()
+
+		m := pgwire.MsgFunctionCall{
+			ArgumentFormats: []pgwire.FormatKind{pgwire.FormatKindText, pgwire.FormatKindBinary},
+			ArgumentValues:  [][]byte{nil, nil, nil},
+		}
+
+		_, err := m.AppendBinary(nil)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrInvalidValue)
+
+		b := []byte{
+			byte(pgwire.MessageKindFunctionCall), 0, 0, 0, 30,
+			0, 0, 0, 7,
+			0, 2, 0, 0, 0, 1,
+			0, 3, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0, 0,
+		}
+
+		err = (&pgwire.MsgFunctionCall{}).UnmarshalBinary(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrInvalidValue)
+	})
+}
+
+func TestMsgPasswordMessage(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindPasswordMessage))
+	buf.AppendInt32(11)
+	buf.AppendString("secret")
+
+	var m pgwire.MsgPasswordMessage
+
+	testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+		require.Equal(t, "secret", m.Password)
+	})
+
+	t.Run("Unterminated", func(t *testing.T) {
+		b := buf.Bytes()
+		b = append(b[:len(b)-1:len(b)-1], 'x')
+
+		err := (&pgwire.MsgPasswordMessage{}).UnmarshalBinary(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	})
+}
+
+func TestMsgQuery(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindQuery))
+	buf.AppendInt32(13)
+	buf.AppendString("SELECT 1")
+
+	var m pgwire.MsgQuery
+
+	testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+		require.Equal(t, "SELECT 1", m.Value)
+	})
+
+	t.Run("Unterminated", func(t *testing.T) {
+		b := buf.Bytes()
+		b = append(b[:len(b)-1:len(b)-1], ';')
+
+		err := (&pgwire.MsgQuery{}).UnmarshalBinary(b)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+	})
+
+	t.Run("EmbeddedNull", func(t *testing.T) {
+		_, err := (&pgwire.MsgQuery{Value: "SELECT 1\x00; DROP TABLE t"}).AppendBinary(nil)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrEmbeddedNull)
+
+		body := pgio.NewBuffer(nil)
+		body.AppendByte(byte(pgwire.MessageKindQuery))
+		body.AppendInt32(14)
+		body.AppendString("SELECT", "1;")
+
+		err = (&pgwire.MsgQuery{}).UnmarshalBinary(body.Bytes())
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueOverflow)
+	})
+}
+
+func TestMsgSASLInitialResponse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Response", func(t *testing.T) {
+		t.Parallel()
+
+		buf := pgio.NewBuffer(nil)
+		buf.AppendByte(byte(pgwire.MessageKindSASLInitialResponse))
+		buf.AppendInt32(36)
+		buf.AppendString("SCRAM-SHA-256")
+		buf.AppendInt32(14)
+		buf.AppendByte([]byte("n,,n=,r=abcdef")...)
+
+		var m pgwire.MsgSASLInitialResponse
+
+		testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+			require.Equal(t, "SCRAM-SHA-256", m.Name)
+			require.Equal(t, []byte("n,,n=,r=abcdef"), m.Response)
+		})
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		t.Parallel()
+
+		buf := pgio.NewBuffer(nil)
+		buf.AppendByte(byte(pgwire.MessageKindSASLInitialResponse))
+		buf.AppendInt32(22)
+		buf.AppendString("SCRAM-SHA-256")
+		buf.AppendInt32(-1)
+
+		var m pgwire.MsgSASLInitialResponse
+
+		testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+			require.Nil(t, m.Response)
+		})
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		buf := pgio.NewBuffer(nil)
+		buf.AppendByte(byte(pgwire.MessageKindSASLInitialResponse))
+		buf.AppendInt32(22)
+		buf.AppendString("SCRAM-SHA-256")
+		buf.AppendInt32(0)
+
+		m := pgwire.MsgSASLInitialResponse{Name: "SCRAM-SHA-256", Response: []byte{}}
+
+		testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+			require.NotNil(t, m.Response)
+			require.Empty(t, m.Response)
+		})
+	})
+
+	t.Run("NegativeLength", func(t *testing.T) {
+		t.Parallel()
+
+		buf := pgio.NewBuffer(nil)
+		buf.AppendByte(byte(pgwire.MessageKindSASLInitialResponse))
+		buf.AppendInt32(22)
+		buf.AppendString("SCRAM-SHA-256")
+		buf.AppendInt32(-2)
+
+		err := (&pgwire.MsgSASLInitialResponse{}).UnmarshalBinary(buf.Bytes())
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrInvalidValue)
+	})
+}
+
+func TestMsgSASLResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]byte{
+		"Empty": {},
+		"Proof": []byte("c=biws,r=nonce,p=proof"),
+		"Large": bytes.Repeat([]byte("0123456789abcdef"), 512),
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := pgio.NewBuffer(nil)
+			buf.AppendByte(byte(pgwire.MessageKindSASLResponse))
+			buf.AppendInt32(int32(4 + len(data)))
+			buf.AppendByte(data...)
+
+			var m pgwire.MsgSASLResponse
+
+			testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+				require.Equal(t, data, m.Data)
+			})
+		})
+	}
+}
+
+func TestMsgSync(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindSync))
+	buf.AppendInt32(4)
+
+	var m pgwire.MsgSync
+
+	testMessage(t, buf.Bytes(), &m, nil)
+}
+
+func TestMsgTerminate(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindTerminate))
+	buf.AppendInt32(4)
+
+	var m pgwire.MsgTerminate
+
+	testMessage(t, buf.Bytes(), &m, nil)
+
+	got, err := pgwire.NewMessageReader(bytes.NewReader(buf.Bytes())).ReadFrontend()
+	require.NoError(t, err)
+	require.Equal(t, &pgwire.MsgTerminate{}, got)
+}
+
+func TestBindText(t *testing.T) {
+	t.Parallel()
+
+	m := pgwire.BindText("portal", "statement", []string{"hello", ""})
+
+	got, err := m.AppendBinary(nil)
+	require.NoError(t, err)
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindBind))
+	buf.AppendInt32(44)
+	buf.AppendString("portal")
+	buf.AppendString("statement")
+	buf.AppendInt16(2)
+	buf.AppendInt16(int16(pgwire.FormatKindText), int16(pgwire.FormatKindText))
+	buf.AppendInt16(2)
+	buf.AppendInt32(5)
+	buf.AppendByte([]byte("hello")...)
+	buf.AppendInt32(0)
+	buf.AppendInt16(0)
+
+	require.Equal(t, buf.Bytes(), got)
+}
+
+func TestBindTextNullable(t *testing.T) {
+	t.Parallel()
+
+	value := "hello"
+
+	m := pgwire.BindTextNullable("", "", []*string{&value, nil})
+
+	got, err := m.AppendBinary(nil)
+	require.NoError(t, err)
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindBind))
+	buf.AppendInt32(29)
+	buf.AppendString("")
+	buf.AppendString("")
+	buf.AppendInt16(2)
+	buf.AppendInt16(int16(pgwire.FormatKindText), int16(pgwire.FormatKindText))
+	buf.AppendInt16(2)
+	buf.AppendInt32(5)
+	buf.AppendByte([]byte("hello")...)
+	buf.AppendInt32(-1)
+	buf.AppendInt16(0)
+
+	require.Equal(t, buf.Bytes(), got)
+}
+
+func TestMD5Password(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t, pgwire.NewAuthenticationMD5PasswordSalt([4]byte{1, 2, 3, 4}))
+	c := pgwire.NewConn(s)
+
+	m, err := c.Receive()
+	require.NoError(t, err)
+
+	req, ok := m.(*pgwire.MsgAuthenticationMD5Password)
+	require.True(t, ok)
+
+	err = c.Send(pgwire.MD5Password("postgres", "secret", req.Salt))
+	require.NoError(t, err)
+
+	got, err := pgwire.NewMessageReader(&s.out).ReadFrontend()
+	require.NoError(t, err)
+	require.Equal(t, &pgwire.MsgPasswordMessage{Password: "md5bb41a296aab6baccb36ff243a562abff"}, got)
+}
+
+func TestCleartextPassword(t *testing.T) {
+	t.Parallel()
+
+	b, err := pgwire.CleartextPassword("secret").AppendBinary(nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{'p', 0, 0, 0, 11, 's', 'e', 'c', 'r', 'e', 't', 0}, b)
+}
+
+func TestNewParse(t *testing.T) {
+	t.Parallel()
+
+	b, err := pgwire.NewParse("s1", "SELECT $1").AppendBinary(nil)
+	require.NoError(t, err)
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindParse))
+	buf.AppendInt32(19)
+	buf.AppendString("s1")
+	buf.AppendString("SELECT $1")
+	buf.AppendInt16(0)
+	require.Equal(t, buf.Bytes(), b)
+}
+
+func TestNewParseTyped(t *testing.T) {
+	t.Parallel()
+
+	b, err := pgwire.NewParseTyped("", "SELECT $1, $2", []int32{23, 25}).AppendBinary(nil)
+	require.NoError(t, err)
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendByte(byte(pgwire.MessageKindParse))
+	buf.AppendInt32(29)
+	buf.AppendString("")
+	buf.AppendString("SELECT $1, $2")
+	buf.AppendInt16(2)
+	buf.AppendInt32(23, 25)
+	require.Equal(t, buf.Bytes(), b)
+}
+
+func TestMsgStartupMessage(t *testing.T) {
+	t.Parallel()
+
+	buf := pgio.NewBuffer(nil)
+	buf.AppendInt32(84)
+	buf.AppendInt32(pgwire.ProtocolVersion3_0)
+	buf.AppendString("user", "postgres")
+	buf.AppendString("database", "app")
+	buf.AppendString("options", "-c search_path=app")
+	buf.AppendString("replication", "database")
+	buf.AppendByte(0)
+
+	var m pgwire.MsgStartupMessage
+
+	testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+		require.Equal(t, pgwire.ProtocolVersion(pgwire.ProtocolVersion3_0), m.ProtocolVersion)
+		require.Equal(t, []pgwire.StartupParameter{
+			{Name: pgwire.ParamUser, Value: "postgres"},
+			{Name: pgwire.ParamDatabase, Value: "app"},
+			{Name: pgwire.ParamOptions, Value: "-c search_path=app"},
+			{Name: pgwire.ParamReplication, Value: "database"},
+		}, m.Parameters)
+
+		user, ok := m.Get(pgwire.ParamUser)
+		require.True(t, ok)
+		require.Equal(t, "postgres", user)
+
+		_, ok = m.Get("application_name")
+		require.False(t, ok)
+	})
+}
+
+func TestMsgStartupMessageSend(t *testing.T) {
+	t.Parallel()
+
+	want := &pgwire.MsgStartupMessage{
+		ProtocolVersion: pgwire.ProtocolVersion(pgwire.ProtocolVersion3_0),
+		Parameters: []pgwire.StartupParameter{
+			{Name: pgwire.ParamUser, Value: "postgres"},
+			{Name: pgwire.ParamDatabase, Value: "app"},
+		},
+	}
+
+	s := newScript(t)
+
+	err := pgwire.NewConn(s).Send(want)
+	require.NoError(t, err)
+
+	var got pgwire.MsgStartupMessage
+
+	err = got.UnmarshalBinary(s.out.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, want, &got)
+}
+
+func TestMsgStartupMessageInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]byte{
+		"Empty":      {},
+		"Length":     {0, 0, 0, 2},
+		"Short":      {0, 0, 0, 7, 0, 3, 0},
+		"Terminator": {0, 0, 0, 13, 0, 3, 0, 0, 'u', 's', 'e', 'r', 0},
+		"Trailing":   {0, 0, 0, 10, 0, 3, 0, 0, 0, 'x'},
+	}
+
+	for name, b := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var m pgwire.MsgStartupMessage
+
+			err := m.UnmarshalBinary(b)
+			require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		})
+	}
+}
+
+func TestMsgParseParameterCount(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Unsigned", func(t *testing.T) {
+		t.Parallel()
+
+		oids := make([]int32, math.MaxUint16)
+		for i := range oids {
+			oids[i] = int32(i)
+		}
+
+		b, err := pgwire.NewParseTyped("", "SELECT", oids).AppendBinary(nil)
+		require.NoError(t, err)
+
+		var m pgwire.MsgParse
+
+		err = m.UnmarshalBinary(b)
+		require.NoError(t, err)
+		require.Equal(t, oids, m.ParameterDataTypes)
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := pgwire.NewParseTyped("", "SELECT", make([]int32, math.MaxUint16+1)).AppendBinary(nil)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueOverflow)
+	})
+}
+
+func TestMsgParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Unnamed", func(t *testing.T) {
+		t.Parallel()
+
+		buf := pgio.NewBuffer(nil)
+		buf.AppendByte(byte(pgwire.MessageKindParse))
+		buf.AppendInt32(16)
+		buf.AppendString("")
+		buf.AppendString("SELECT 1")
+		buf.AppendInt16(0)
+
+		var m pgwire.MsgParse
+
+		testMessage(t, buf.Bytes(), &m, func(t *testing.T) {
+			require.Empty(t, m.DestinationStatementName)
+			require.Equal(t, "SELECT 1", m.Query)
+			require.Empty(t, m.ParameterDataTypes)
+		})
+	})
+
+	t.Run("CountMismatch", func(t *testing.T) {
+		t.Parallel()
+
+		short := pgio.NewBuffer(nil)
+		short.AppendByte(byte(pgwire.MessageKindParse))
+		short.AppendInt32(21)
+		short.AppendString("")
+		short.AppendString("SELECT $1")
+		short.AppendInt16(2)
+		short.AppendInt32(23)
+
+		err := (&pgwire.MsgParse{}).UnmarshalBinary(short.Bytes())
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+
+		long := pgio.NewBuffer(nil)
+		long.AppendByte(byte(pgwire.MessageKindParse))
+		long.AppendInt32(25)
+		long.AppendString("")
+		long.AppendString("SELECT $1")
+		long.AppendInt16(1)
+		long.AppendInt32(23, 25)
+
+		err = (&pgwire.MsgParse{}).UnmarshalBinary(long.Bytes())
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueOverflow)
+	})
+}
+
+func TestFrontendLargeCount(t *testing.T) {
+	t.Parallel()
+
+	frame := func(kind pgwire.MessageKind, body ...byte) []byte {
+		buf := pgio.NewBuffer(nil)
+		buf.AppendByte(byte(kind))
+		buf.AppendInt32(int32(4 + len(body)))
+		buf.AppendByte(body...)
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name string
+		m    pgwire.Message
+		b    []byte
+	}{
+		{"FunctionCallFormats", &pgwire.MsgFunctionCall{}, frame(pgwire.MessageKindFunctionCall, 0, 0, 0, 1, 0x7f, 0xff, 0, 0)},
+		{"FunctionCallArguments", &pgwire.MsgFunctionCall{}, frame(pgwire.MessageKindFunctionCall, 0, 0, 0, 1, 0, 0, 0x7f, 0xff, 0, 0, 0, 0)},
+		{"Parse", &pgwire.MsgParse{}, frame(pgwire.MessageKindParse, 0, 0, 0xff, 0xff, 0, 0, 0, 0)},
+		{"BindFormats", &pgwire.MsgBind{}, frame(pgwire.MessageKindBind, 0, 0, 0x7f, 0xff, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.m.UnmarshalBinary(tt.b)
+			require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+			require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package pgwire
+
+import (
+	"gopsql/pgio"
+	"io"
+)
+
+const negotiationRejected byte = 'N'
+
+// SSLResponse is the single byte a server sends in reply to an SSLRequest.
+type SSLResponse byte
+
+const (
+	// SSLAccepted means the client should start a TLS handshake, for
+	// example by wrapping the connection in tls.Client.
+	SSLAccepted SSLResponse = 'S'
+	// SSLRejected means the client may continue without TLS on the same
+	// connection or close it.
+	SSLRejected SSLResponse = SSLResponse(negotiationRejected)
+)
+
+// GSSENCResponse is the single byte a server sends in reply to a
+// GSSENCRequest.
+type GSSENCResponse byte
+
+const (
+	// GSSENCAccepted means the client should perform the GSSAPI encryption
+	// handshake before sending the startup message.
+	GSSENCAccepted GSSENCResponse = 'G'
+	// GSSENCRejected means the client may continue unencrypted on the same
+	// connection, try an SSLRequest, or close it.
+	GSSENCRejected GSSENCResponse = GSSENCResponse(negotiationRejected)
+)
+
+// RequestSSL sends an SSLRequest and reads the single byte reply. It reports
+// true when the server is willing to proceed with TLS. Exactly one byte is
+// read so the connection can be handed to tls.Client afterwards.
+func RequestSSL(conn io.ReadWriter) (bool, error) {
+	b, err := (&MsgSSLRequest{}).AppendBinary(nil)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = conn.Write(b)
+	if err != nil {
+		return false, err
+	}
+
+	response, err := ReadSSLResponse(conn)
+	if err != nil {
+		return false, err
+	}
+	return response == SSLAccepted, nil
+}
+
+// RequestGSSEnc sends a GSSENCRequest and reads the single byte reply. It
+// reports true when the server is willing to proceed with GSSAPI encryption,
+// which the caller must then set up before sending the startup message.
+func RequestGSSEnc(conn io.ReadWriter) (bool, error) {
+	b, err := (&MsgGSSENCRequest{}).AppendBinary(nil)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = conn.Write(b)
+	if err != nil {
+		return false, err
+	}
+
+	response, err := ReadGSSENCResponse(conn)
+	if err != nil {
+		return false, err
+	}
+	return response == GSSENCAccepted, nil
+}
+
+// ReadSSLResponse reads the reply to an SSLRequest, consuming exactly one
+// byte.
+func ReadSSLResponse(r io.Reader) (SSLResponse, error) {
+	reply, err := readNegotiationReply(r, byte(SSLAccepted))
+	if err != nil {
+		return 0, err
+	}
+	return SSLResponse(reply), nil
+}
+
+// ReadGSSENCResponse reads the reply to a GSSENCRequest, consuming exactly
+// one byte.
+func ReadGSSENCResponse(r io.Reader) (GSSENCResponse, error) {
+	reply, err := readNegotiationReply(r, byte(GSSENCAccepted))
+	if err != nil {
+		return 0, err
+	}
+	return GSSENCResponse(reply), nil
+}
+
+func readNegotiationReply(r io.Reader, accepted byte) (byte, error) {
+	var reply [1]byte
+
+	_, err := io.ReadFull(r, reply[:])
+	if err != nil {
+		return 0, err
+	}
+
+	switch reply[0] {
+	case accepted, negotiationRejected:
+		return reply[0], nil
+	}
+	return 0, invalidFormat(pgio.ErrUnknownCode)
+}
@@ -0,0 +1,106 @@
+package pgwire_test
+
+import (
+	"bytes"
+	"gopsql/pgwire"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestSSL(t *testing.T) {
+	t.Parallel()
+
+	request := appendMessages(t, &pgwire.MsgSSLRequest{})
+
+	t.Run("Accepted", func(t *testing.T) {
+		s := &script{in: bytes.NewReader([]byte("S"))}
+
+		ok, err := pgwire.RequestSSL(s)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, request, s.out.Bytes())
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		s := &script{in: bytes.NewReader([]byte("N"))}
+
+		ok, err := pgwire.RequestSSL(s)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, request, s.out.Bytes())
+	})
+
+	t.Run("Unexpected", func(t *testing.T) {
+		s := &script{in: bytes.NewReader([]byte("E"))}
+
+		_, err := pgwire.RequestSSL(s)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	})
+}
+
+func TestRequestGSSEnc(t *testing.T) {
+	t.Parallel()
+
+	request := appendMessages(t, &pgwire.MsgGSSENCRequest{})
+
+	t.Run("Accepted", func(t *testing.T) {
+		s := &script{in: bytes.NewReader([]byte("G"))}
+
+		ok, err := pgwire.RequestGSSEnc(s)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, request, s.out.Bytes())
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		s := &script{in: bytes.NewReader([]byte("N"))}
+
+		ok, err := pgwire.RequestGSSEnc(s)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, request, s.out.Bytes())
+	})
+
+	t.Run("Unexpected", func(t *testing.T) {
+		s := &script{in: bytes.NewReader([]byte("S"))}
+
+		_, err := pgwire.RequestGSSEnc(s)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	})
+}
+
+func TestReadSSLResponse(t *testing.T) {
+	t.Parallel()
+
+	r := bytes.NewReader([]byte("SN"))
+
+	response, err := pgwire.ReadSSLResponse(r)
+	require.NoError(t, err)
+	require.Equal(t, pgwire.SSLAccepted, response)
+
+	// Only one byte is consumed per reply.
+	require.Equal(t, 1, r.Len())
+
+	response, err = pgwire.ReadSSLResponse(r)
+	require.NoError(t, err)
+	require.Equal(t, pgwire.SSLRejected, response)
+}
+
+func TestReadGSSENCResponse(t *testing.T) {
+	t.Parallel()
+
+	r := bytes.NewReader([]byte("GNS"))
+
+	response, err := pgwire.ReadGSSENCResponse(r)
+	require.NoError(t, err)
+	require.Equal(t, pgwire.GSSENCAccepted, response)
+
+	response, err = pgwire.ReadGSSENCResponse(r)
+	require.NoError(t, err)
+	require.Equal(t, pgwire.GSSENCRejected, response)
+
+	// 'S' answers an SSLRequest, not a GSSENCRequest.
+	_, err = pgwire.ReadGSSENCResponse(r)
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+}
@@ -0,0 +1,84 @@
+package pgwire
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Listen subscribes the session to channel. The name is quoted, so it is
+// matched exactly, including case.
+func (c *Conn) Listen(channel string) error {
+	_, err := c.SimpleQuery("LISTEN " + quoteIdentifier(channel))
+	return err
+}
+
+// SetNoticeHandler sets the function WaitNotification calls for each
+// NoticeResponse it reads while waiting. The message is only valid for the
+// duration of the call; use Clone to keep it.
+func (c *Conn) SetNoticeHandler(fn func(*MsgNoticeResponse)) {
+	c.onNotice = fn
+}
+
+// SetParameterStatusHandler sets the function WaitNotification calls for each
+// ParameterStatus it reads while waiting.
+func (c *Conn) SetParameterStatusHandler(fn func(*MsgParameterStatus)) {
+	c.onParameterStatus = fn
+}
+
+// WaitNotification reads until the server delivers a notification on a
+// channel the session listens on. Notices and parameter changes read in the
+// meantime are passed to their handlers.
+//
+// When ctx is done, the pending read is interrupted through the connection's
+// read deadline and ctx.Err() is returned. A message may then be left partly
+// read, so the Conn should be closed. If the connection does not support
+// deadlines, ctx is only checked before each read.
+func (c *Conn) WaitNotification(ctx context.Context) (*MsgNotificationResponse, error) {
+	interrupted := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		c.SetReadDeadline(time.Unix(1, 0))
+		close(interrupted)
+	})
+	defer func() {
+		if !stop() {
+			<-interrupted
+			c.SetReadDeadline(time.Time{})
+		}
+	}()
+
+	for {
+		err := ctx.Err()
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := c.receiveBackend()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+
+		switch m := m.(type) {
+		case *MsgNotificationResponse:
+			return m, nil
+		case *MsgNoticeResponse:
+			if c.onNotice != nil {
+				c.onNotice(m)
+			}
+		case *MsgParameterStatus:
+			if c.onParameterStatus != nil {
+				c.onParameterStatus(m)
+			}
+		default:
+			return nil, fmt.Errorf("%w: %T while waiting for a notification", ErrUnexpectedKind, m)
+		}
+	}
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,85 @@
+package pgwire_test
+
+import (
+	"context"
+	"gopsql/pgwire"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnListen(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgCommandComplete{Tag: "LISTEN"},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	err := c.Listen(`Jobs "new"`)
+	require.NoError(t, err)
+	require.Equal(t, appendMessages(t, &pgwire.MsgQuery{Value: `LISTEN "Jobs ""new"""`}), s.out.Bytes())
+}
+
+func TestConnWaitNotification(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgNoticeResponse{Fields: []byte{'M'}, Values: []string{"first"}},
+		&pgwire.MsgParameterStatus{Name: "TimeZone", Value: "UTC"},
+		&pgwire.MsgNoticeResponse{Fields: []byte{'M'}, Values: []string{"second"}},
+		&pgwire.MsgNotificationResponse{ProcessID: 42, Channel: "jobs", Payload: "7"},
+	)
+
+	c := pgwire.NewConn(s)
+
+	var notices []string
+	c.SetNoticeHandler(func(m *pgwire.MsgNoticeResponse) {
+		notices = append(notices, m.Clone().Values[0])
+	})
+
+	var params []string
+	c.SetParameterStatusHandler(func(m *pgwire.MsgParameterStatus) {
+		params = append(params, m.Name+"="+m.Value)
+	})
+
+	m, err := c.WaitNotification(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(42), m.ProcessID)
+	require.Equal(t, "jobs", m.Channel)
+	require.Equal(t, "7", m.Payload)
+	require.Equal(t, []string{"first", "second"}, notices)
+	require.Equal(t, []string{"TimeZone=UTC"}, params)
+}
+
+func TestConnWaitNotificationCancel(t *testing.T) {
+	t.Parallel()
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	c := pgwire.NewConn(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.WaitNotification(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestConnWaitNotificationCanceled(t *testing.T) {
+	t.Parallel()
+
+	c := pgwire.NewConn(newScript(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := c.WaitNotification(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,95 @@
+package pgwire
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParameterSet tracks the run-time parameters the server reports with
+// ParameterStatus, such as server_version and TimeZone. The server may
+// report a change at any time, so one goroutine may Apply updates while
+// others read.
+type ParameterSet struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// Apply records the value reported by m.
+func (x *ParameterSet) Apply(m *MsgParameterStatus) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.values == nil {
+		x.values = make(map[string]string)
+	}
+	x.values[m.Name] = m.Value
+}
+
+func (x *ParameterSet) Get(name string) (string, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	value, ok := x.values[name]
+	return value, ok
+}
+
+// All returns a copy of every parameter reported so far.
+func (x *ParameterSet) All() map[string]string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return maps.Clone(x.values)
+}
+
+// ServerVersion returns the leading numbers of server_version, such as 17
+// and 2 for "17.2" or "17.2 (Debian 17.2-1)". A version without a minor
+// number, such as "18beta1", has a minor of 0.
+func (x *ParameterSet) ServerVersion() (major, minor int, ok bool) {
+	version, ok := x.Get("server_version")
+	if !ok {
+		return 0, 0, false
+	}
+
+	major, rest, ok := leadingInt(version)
+	if !ok {
+		return 0, 0, false
+	}
+
+	if rest, found := strings.CutPrefix(rest, "."); found {
+		minor, _, _ = leadingInt(rest)
+	}
+	return major, minor, true
+}
+
+// TimeZone returns the location named by the TimeZone parameter. It reports
+// false when the parameter is missing or the name is not in the local time
+// zone database.
+func (x *ParameterSet) TimeZone() (*time.Location, bool) {
+	name, ok := x.Get("TimeZone")
+	if !ok {
+		return nil, false
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	return loc, true
+}
+
+// leadingInt parses the decimal digits at the start of s.
+func leadingInt(s string) (int, string, bool) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+
+	n, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return 0, s, false
+	}
+	return n, s[i:], true
+}
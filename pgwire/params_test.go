@@ -0,0 +1,95 @@
+package pgwire_test
+
+import (
+	"gopsql/pgwire"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParameterSet(t *testing.T) {
+	t.Parallel()
+
+	var ps pgwire.ParameterSet
+
+	_, ok := ps.Get("TimeZone")
+	require.False(t, ok)
+
+	_, _, ok = ps.ServerVersion()
+	require.False(t, ok)
+
+	ps.Apply(&pgwire.MsgParameterStatus{Name: "TimeZone", Value: "UTC"})
+	ps.Apply(&pgwire.MsgParameterStatus{Name: "TimeZone", Value: "Europe/Paris"})
+	ps.Apply(&pgwire.MsgParameterStatus{Name: "client_encoding", Value: "UTF8"})
+
+	value, ok := ps.Get("TimeZone")
+	require.True(t, ok)
+	require.Equal(t, "Europe/Paris", value)
+
+	loc, ok := ps.TimeZone()
+	require.True(t, ok)
+	require.Equal(t, "Europe/Paris", loc.String())
+
+	require.Equal(t, map[string]string{
+		"TimeZone":        "Europe/Paris",
+		"client_encoding": "UTF8",
+	}, ps.All())
+
+	ps.Apply(&pgwire.MsgParameterStatus{Name: "TimeZone", Value: "Not/AZone"})
+
+	_, ok = ps.TimeZone()
+	require.False(t, ok)
+}
+
+func TestParameterSetServerVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		version      string
+		major, minor int
+		ok           bool
+	}{
+		{"17.2", 17, 2, true},
+		{"16.4 (Debian 16.4-1.pgdg120+1)", 16, 4, true},
+		{"9.6.24", 9, 6, true},
+		{"18beta1", 18, 0, true},
+		{"devel", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		var ps pgwire.ParameterSet
+		ps.Apply(&pgwire.MsgParameterStatus{Name: "server_version", Value: tt.version})
+
+		major, minor, ok := ps.ServerVersion()
+		require.Equal(t, tt.ok, ok, tt.version)
+		require.Equal(t, tt.major, major, tt.version)
+		require.Equal(t, tt.minor, minor, tt.version)
+	}
+}
+
+func TestParameterSetConcurrent(t *testing.T) {
+	t.Parallel()
+
+	var ps pgwire.ParameterSet
+	var wg sync.WaitGroup
+
+	wg.Go(func() {
+		for range 100 {
+			ps.Apply(&pgwire.MsgParameterStatus{Name: "application_name", Value: "app"})
+		}
+	})
+
+	for range 4 {
+		wg.Go(func() {
+			for range 100 {
+				ps.Get("application_name")
+				ps.All()
+			}
+		})
+	}
+	wg.Wait()
+
+	value, _ := ps.Get("application_name")
+	require.Equal(t, "app", value)
+}
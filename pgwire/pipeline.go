@@ -0,0 +1,57 @@
+package pgwire
+
+// PipelineQuery is one operation of a Pipeline. A nil parameter is sent as
+// NULL and all parameters and result columns use the text format.
+type PipelineQuery struct {
+	SQL    string
+	Params [][]byte
+}
+
+// Pipeline runs queries using the extended query protocol without waiting
+// for each one to finish. The operations are written in one batch separated
+// by Flush, so the server sends each result as soon as it is ready, and
+// ended by a single Sync. It returns one result per operation that
+// completed. After an error the server skips the remaining operations, so
+// the results stop short and the error is returned with them.
+func (c *Conn) Pipeline(queries []PipelineQuery) ([]*QueryResult, error) {
+	msgs := make([]Message, 0, len(queries)*5)
+
+	for i, q := range queries {
+		if i > 0 {
+			msgs = append(msgs, &MsgFlush{})
+		}
+		msgs = append(msgs,
+			&MsgParse{Query: q.SQL},
+			&MsgBind{ParameterData: q.Params},
+			&MsgDescribe{ObjectKind: ObjectKindPortal},
+			&MsgExecute{},
+		)
+	}
+	msgs = append(msgs, &MsgSync{})
+
+	err := c.Send(msgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	var rc resultCollector
+	results := make([]*QueryResult, 0, len(queries))
+
+	for {
+		m, err := c.receiveBackend()
+		if err != nil {
+			return nil, err
+		}
+
+		done := rc.add(m)
+
+		if rc.last != nil {
+			results = append(results, rc.last)
+			rc.last = nil
+		}
+
+		if done {
+			return results, rc.err
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package pgwire_test
+
+import (
+	"gopsql/pgwire"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnPipeline(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgBindComplete{},
+		&pgwire.MsgRowDescription{
+			Names:     []string{"a"},
+			Tables:    []int32{0},
+			Columns:   []int16{0},
+			DataTypes: []int32{23},
+			Sizes:     []int16{4},
+			Modifiers: []int32{-1},
+			Formats:   []int16{0},
+		},
+		&pgwire.MsgDataRow{Columns: [][]byte{[]byte("1")}},
+		&pgwire.MsgCommandComplete{Tag: "SELECT 1"},
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgBindComplete{},
+		&pgwire.MsgNoData{},
+		&pgwire.MsgCommandComplete{Tag: "INSERT 0 1"},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	results, err := c.Pipeline([]pgwire.PipelineQuery{
+		{SQL: "SELECT 1"},
+		{SQL: "INSERT INTO t VALUES ($1)", Params: [][]byte{[]byte("x")}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, [][][]byte{{[]byte("1")}}, results[0].Rows)
+	require.Equal(t, "SELECT 1", results[0].Tag)
+	require.Nil(t, results[1].Description)
+	require.Equal(t, "INSERT 0 1", results[1].Tag)
+
+	r := pgwire.NewMessageReader(&s.out)
+
+	var kinds []pgwire.MessageKind
+
+	for {
+		m, err := r.ReadFrontend()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		b, err := m.AppendBinary(nil)
+		require.NoError(t, err)
+		kinds = append(kinds, pgwire.MessageKind(b[0]))
+	}
+
+	require.Equal(t, []pgwire.MessageKind{
+		pgwire.MessageKindParse,
+		pgwire.MessageKindBind,
+		pgwire.MessageKindDescribe,
+		pgwire.MessageKindExecute,
+		pgwire.MessageKindFlush,
+		pgwire.MessageKindParse,
+		pgwire.MessageKindBind,
+		pgwire.MessageKindDescribe,
+		pgwire.MessageKindExecute,
+		pgwire.MessageKindSync,
+	}, kinds)
+}
+
+func TestConnPipelineError(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgBindComplete{},
+		&pgwire.MsgNoData{},
+		&pgwire.MsgCommandComplete{Tag: "UPDATE 2"},
+		&pgwire.MsgErrorResponse{
+			Fields: []byte{'S', 'C', 'M'},
+			Values: []string{"ERROR", "42P01", `relation "missing" does not exist`},
+		},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	results, err := c.Pipeline([]pgwire.PipelineQuery{
+		{SQL: "UPDATE t SET a = 1"},
+		{SQL: "SELECT * FROM missing"},
+		{SQL: "SELECT 1"},
+	})
+
+	var pgErr *pgwire.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "42", pgErr.SQLStateClass())
+	require.Len(t, results, 1)
+	require.Equal(t, "UPDATE 2", results[0].Tag)
+}
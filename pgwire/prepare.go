@@ -0,0 +1,65 @@
+package pgwire
+
+// PreparedStatement is a named statement created by Prepare, with the
+// metadata the server reported for it.
+type PreparedStatement struct {
+	Name string
+	SQL  string
+
+	// Parameters holds the data type OID of each parameter.
+	Parameters []int32
+
+	// Description is nil for statements that return no rows.
+	Description *MsgRowDescription
+}
+
+// Prepare parses sql into a named statement and describes it, using Parse,
+// Describe and Sync. Statements are cached by SQL text, so preparing the
+// same sql again returns the earlier statement without a round trip.
+// CloseStatement removes a statement from the cache.
+func (c *Conn) Prepare(sql string) (*PreparedStatement, error) {
+	if ps, ok := c.statements[sql]; ok {
+		return ps, nil
+	}
+
+	ps := &PreparedStatement{Name: c.NextStatementName(), SQL: sql}
+
+	err := c.Send(
+		&MsgParse{DestinationStatementName: ps.Name, Query: sql},
+		&MsgDescribe{ObjectKind: ObjectKindStatement, ObjectName: ps.Name},
+		&MsgSync{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var pgErr *PgError
+
+	for {
+		m, err := c.receiveBackend()
+		if err != nil {
+			return nil, err
+		}
+
+		switch m := m.(type) {
+		case *MsgParameterDescription:
+			ps.Parameters = m.Parameters
+		case *MsgRowDescription:
+			ps.Description = m
+		case *MsgErrorResponse:
+			if pgErr == nil {
+				pgErr = newPgError(m)
+			}
+		case *MsgReadyForQuery:
+			if pgErr != nil {
+				return nil, pgErr
+			}
+
+			if c.statements == nil {
+				c.statements = make(map[string]*PreparedStatement)
+			}
+			c.statements[sql] = ps
+			return ps, nil
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package pgwire_test
+
+import (
+	"gopsql/pgwire"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnPrepare(t *testing.T) {
+	t.Parallel()
+
+	desc := pgwire.NewRowDescription([]pgwire.ColumnDescription{
+		{Name: "name", DataType: 25, Size: -1, Modifier: -1},
+	})
+
+	s := newScript(t,
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgParameterDescription{Parameters: []int32{23}},
+		desc,
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgParameterDescription{Parameters: []int32{}},
+		&pgwire.MsgNoData{},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	ps, err := c.Prepare("SELECT name FROM t WHERE id = $1")
+	require.NoError(t, err)
+	require.Equal(t, &pgwire.PreparedStatement{
+		Name:        "stmt_1",
+		SQL:         "SELECT name FROM t WHERE id = $1",
+		Parameters:  []int32{23},
+		Description: desc,
+	}, ps)
+
+	// The same SQL is served from the cache without another round trip.
+	again, err := c.Prepare("SELECT name FROM t WHERE id = $1")
+	require.NoError(t, err)
+	require.Same(t, ps, again)
+
+	other, err := c.Prepare("CHECKPOINT")
+	require.NoError(t, err)
+	require.Equal(t, "stmt_2", other.Name)
+	require.Nil(t, other.Description)
+
+	require.Equal(t, appendMessages(t,
+		&pgwire.MsgParse{DestinationStatementName: "stmt_1", Query: "SELECT name FROM t WHERE id = $1"},
+		&pgwire.MsgDescribe{ObjectKind: pgwire.ObjectKindStatement, ObjectName: "stmt_1"},
+		&pgwire.MsgSync{},
+		&pgwire.MsgParse{DestinationStatementName: "stmt_2", Query: "CHECKPOINT"},
+		&pgwire.MsgDescribe{ObjectKind: pgwire.ObjectKindStatement, ObjectName: "stmt_2"},
+		&pgwire.MsgSync{},
+	), s.out.Bytes())
+}
+
+func TestConnPrepareError(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgErrorResponse{Fields: []byte("SCM"), Values: []string{"ERROR", "42601", "syntax error"}},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgParameterDescription{Parameters: []int32{}},
+		&pgwire.MsgNoData{},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	_, err := c.Prepare("SELEC 1")
+
+	var pgErr *pgwire.PgError
+	require.ErrorAs(t, err, &pgErr)
+
+	// A failed statement is not cached, so preparing it again retries.
+	_, err = c.Prepare("SELEC 1")
+	require.NoError(t, err)
+}
+
+func TestConnPrepareAfterClose(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgParameterDescription{Parameters: []int32{}},
+		&pgwire.MsgNoData{},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		&pgwire.MsgCloseComplete{},
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgParameterDescription{Parameters: []int32{}},
+		&pgwire.MsgNoData{},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	ps, err := c.Prepare("CHECKPOINT")
+	require.NoError(t, err)
+
+	err = c.CloseStatement(ps.Name)
+	require.NoError(t, err)
+
+	again, err := c.Prepare("CHECKPOINT")
+	require.NoError(t, err)
+	require.Equal(t, "stmt_2", again.Name)
+}
+
+func TestConnPrepareAfterFailedClose(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgParameterDescription{Parameters: []int32{}},
+		&pgwire.MsgNoData{},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		&pgwire.MsgErrorResponse{Fields: []byte("SCM"), Values: []string{"ERROR", "25P02", "current transaction is aborted"}},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindError)},
+	)
+
+	c := pgwire.NewConn(s)
+
+	ps, err := c.Prepare("CHECKPOINT")
+	require.NoError(t, err)
+
+	err = c.CloseStatement(ps.Name)
+
+	var pgErr *pgwire.PgError
+	require.ErrorAs(t, err, &pgErr)
+
+	// The statement may still exist on the server, so it stays cached
+	// rather than being prepared a second time.
+	again, err := c.Prepare("CHECKPOINT")
+	require.NoError(t, err)
+	require.Same(t, ps, again)
+}
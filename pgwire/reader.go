@@ -0,0 +1,605 @@
+package pgwire
+
+import (
+	"bufio"
+	"fmt"
+	"gopsql/pgio"
+	"io"
+
+	"golang.org/x/text/encoding"
+)
+
+const smallBodySize = 64
+
+// maxStartupLength is the largest startup packet the server accepts. The
+// length is read before anything about the client is known, so it is bounded
+// the same way here.
+const maxStartupLength = 10000
+
+// MaxMessageLength is the largest message length, including the length field
+// itself, that a reader accepts. It matches the largest allocation the server
+// allows, which bounds everything it sends or accepts.
+const MaxMessageLength = 1<<30 - 1
+
+type MessageReader struct {
+	r       *bufio.Reader
+	header  [sizeMessageKind + sizeMessageLength]byte
+	small   [sizeMessageKind + sizeMessageLength + smallBodySize]byte
+	decoder *encoding.Decoder
+
+	// formats of the columns of the current result, used to find the text
+	// format DataRow columns that need transcoding. It is nil outside a
+	// result and after the RowDescription of a statement Describe, whose
+	// formats are not yet known.
+	formats []int16
+
+	// describingStatement is set by the ParameterDescription that starts
+	// the reply to a statement Describe.
+	describingStatement bool
+
+	interned    map[string]string
+	internLimit int
+
+	strict bool
+
+	budget    int64
+	bytesRead int64
+}
+
+func NewMessageReader(r io.Reader) *MessageReader {
+	return &MessageReader{
+		r: bufio.NewReader(r),
+	}
+}
+
+// SetEncoding makes ReadBackend transcode string fields and text format
+// DataRow columns from enc to UTF-8, for servers whose client_encoding is not
+// UTF8. A nil enc restores the default passthrough.
+func (x *MessageReader) SetEncoding(enc encoding.Encoding) {
+	if enc == nil {
+		x.decoder = nil
+		return
+	}
+	x.decoder = enc.NewDecoder()
+}
+
+// SetBudget limits the total size of the frames the reader accepts over its
+// lifetime to n bytes. A frame that would take the total past n is rejected
+// with ErrBudgetExceeded before its body is read, which leaves the reader in
+// the middle of the stream, so the connection should be closed. Zero or less
+// removes the limit.
+func (x *MessageReader) SetBudget(n int64) {
+	x.budget = n
+}
+
+// BytesRead returns the total size of the frames the reader has accepted,
+// including headers.
+func (x *MessageReader) BytesRead() int64 {
+	return x.bytesRead
+}
+
+// charge counts a frame of n bytes against the budget.
+func (x *MessageReader) charge(n int) error {
+	if x.budget > 0 && x.bytesRead+int64(n) > x.budget {
+		return fmt.Errorf("%w: %d byte frame after %d of %d bytes", ErrBudgetExceeded, n, x.bytesRead, x.budget)
+	}
+	x.bytesRead += int64(n)
+	return nil
+}
+
+// Next reads the next typed message frame, including the kind byte and
+// length, from the underlying reader.
+func (x *MessageReader) Next() (Frame, error) {
+	length, err := x.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	return x.readFrame(make([]byte, sizeMessageKind+length))
+}
+
+// Peek returns the kind of the next message without consuming it.
+func (x *MessageReader) Peek() (MessageKind, error) {
+	b, err := x.r.Peek(sizeMessageKind)
+	if err != nil {
+		return 0, err
+	}
+	return MessageKind(b[0]), nil
+}
+
+// Skip discards the next message frame without decoding or buffering its
+// body and returns its kind. Use Next to keep the raw bytes instead.
+func (x *MessageReader) Skip() (MessageKind, error) {
+	length, err := x.readHeader()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = x.r.Discard(length - sizeMessageLength)
+	if err != nil {
+		return 0, err
+	}
+	return MessageKind(x.header[0]), nil
+}
+
+// SetStrict makes ReadBackend reject DataRow messages with bytes after the
+// last column instead of ignoring them, and makes every read reject a frame
+// whose kind is not a known message kind before reading its body.
+// CompleteStartup on a strict reader validates each ParameterStatus.
+func (x *MessageReader) SetStrict(strict bool) {
+	x.strict = strict
+}
+
+// ReadBackend reads the next frame and decodes it into the matching backend
+// message type, or MsgUnknown for a kind it does not know unless the reader
+// is strict.
+func (x *MessageReader) ReadBackend() (Backend, error) {
+	length, err := x.readHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	var frame []byte
+
+	// Small messages whose decoded form does not reference the frame are
+	// read into a buffer owned by the reader, avoiding an allocation per
+	// message for streams of ReadyForQuery, BindComplete and the like.
+	if length-sizeMessageLength <= smallBodySize && copiesFrame(MessageKind(x.header[0])) {
+		frame = x.small[:sizeMessageKind+length]
+	} else {
+		frame = make([]byte, sizeMessageKind+length)
+	}
+
+	frame, err = x.readFrame(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := x.parseBackend(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	x.trackFormats(m)
+
+	if x.decoder != nil {
+		err = x.transcode(m)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if x.interned != nil {
+		x.intern(m)
+	}
+	return m, nil
+}
+
+// ReadFrontend reads the next typed frame and decodes it into the matching
+// frontend message type, or MsgUnknown for a kind it does not know unless
+// the reader is strict. Startup packets have no kind byte and are not read
+// by ReadFrontend.
+func (x *MessageReader) ReadFrontend() (Frontend, error) {
+	frame, err := x.Next()
+	if err != nil {
+		return nil, err
+	}
+	return x.parseFrontend(frame)
+}
+
+// ReadAuthResponse reads the client's reply to the authentication request
+// req. Every reply shares the 'p' kind byte, so the request decides which
+// message the frame is decoded as.
+func (x *MessageReader) ReadAuthResponse(req Backend) (Frontend, error) {
+	frame, err := x.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	var m Frontend
+
+	switch req.(type) {
+	case *MsgAuthenticationCleartextPassword, *MsgAuthenticationMD5Password:
+		m = &MsgPasswordMessage{}
+	case *MsgAuthenticationSASL:
+		m = &MsgSASLInitialResponse{}
+	case *MsgAuthenticationSASLContinue:
+		m = &MsgSASLResponse{}
+	case *MsgAuthenticationGSS, *MsgAuthenticationGSSContinue, *MsgAuthenticationSSPI:
+		m = &MsgGSSResponse{}
+	default:
+		return nil, fmt.Errorf("%w: %T expects no response", ErrUnexpectedKind, req)
+	}
+
+	err = m.UnmarshalBinary(frame)
+	if err != nil {
+		return nil, decodeError(frame[0], m, err)
+	}
+	return m, nil
+}
+
+// ReadStartup reads the first packet of a connection on the server side.
+// Startup packets have no kind byte, so the packet is identified by the code
+// following its length: SSLRequest, GSSENCRequest and CancelRequest have
+// their own codes and anything else is read as a StartupMessage carrying a
+// protocol version.
+func (x *MessageReader) ReadStartup() (Frontend, error) {
+	const sizeCode = 4
+
+	_, err := io.ReadFull(x.r, x.header[:sizeMessageLength])
+	if err != nil {
+		return nil, err
+	}
+
+	length, _, err := pgio.ShiftInt32(x.header[:sizeMessageLength])
+	if err != nil {
+		return nil, invalidFormat(err)
+	}
+
+	if length < sizeMessageLength+sizeCode {
+		return nil, invalidFormat(pgio.ErrValueUnderflow)
+	}
+
+	if length > maxStartupLength {
+		return nil, invalidFormat(pgio.ErrValueOverflow)
+	}
+
+	err = x.charge(int(length))
+	if err != nil {
+		return nil, err
+	}
+
+	frame := make([]byte, length)
+	n := copy(frame, x.header[:sizeMessageLength])
+
+	_, err = io.ReadFull(x.r, frame[n:])
+	if err != nil {
+		return nil, err
+	}
+
+	code, _, err := pgio.ShiftInt32(frame[n:])
+	if err != nil {
+		return nil, invalidFormat(err)
+	}
+
+	var m Frontend
+
+	switch code {
+	case CodeSSLRequest:
+		m = &MsgSSLRequest{}
+	case CodeEncryptionRequest:
+		m = &MsgGSSENCRequest{}
+	case CodeCancelRequest:
+		m = &MsgCancelRequest{}
+	default:
+		m = &MsgStartupMessage{}
+	}
+
+	err = m.UnmarshalBinary(frame)
+	if err != nil {
+		return nil, fmt.Errorf("decode %T: %w", m, err)
+	}
+	return m, nil
+}
+
+// readHeader reads the kind and length of the next frame into x.header and
+// returns the length. The array lives in the reader because a local one
+// would escape to the heap through io.ReadFull.
+func (x *MessageReader) readHeader() (int, error) {
+	_, err := io.ReadFull(x.r, x.header[:])
+	if err != nil {
+		return 0, err
+	}
+
+	// No message has a zero kind byte. It almost always means the reader
+	// lost its place in the stream, so it is never passed through as
+	// MsgUnknown.
+	if x.header[0] == 0 {
+		return 0, fmt.Errorf("%w: zero message kind", invalidFormat(pgio.ErrInvalidValue))
+	}
+
+	length, _, err := pgio.ShiftInt32(x.header[sizeMessageKind:])
+	if err != nil {
+		return 0, invalidFormat(err)
+	}
+
+	if x.strict {
+		err = ValidateFrame(x.header[0], length)
+	} else {
+		err = validateLength(length)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	err = x.charge(sizeMessageKind + int(length))
+	if err != nil {
+		return 0, err
+	}
+	return int(length), nil
+}
+
+// ValidateFrame checks a frame header before its body is read: kind must be
+// a message kind of either direction and length must be between 4 and
+// MaxMessageLength.
+func ValidateFrame(kind byte, length int32) error {
+	if kindNames[kind] == "" {
+		return decodeError(kind, nil, invalidFormat(pgio.ErrUnknownMessageType))
+	}
+	return validateLength(length)
+}
+
+func validateLength(length int32) error {
+	if length < sizeMessageLength {
+		return invalidFormat(pgio.ErrValueUnderflow)
+	}
+
+	if length > MaxMessageLength {
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
+	return nil
+}
+
+// readFrame copies the header read by readHeader into frame and fills the
+// rest of it with the message body.
+func (x *MessageReader) readFrame(frame []byte) ([]byte, error) {
+	n := copy(frame, x.header[:])
+
+	_, err := io.ReadFull(x.r, frame[n:])
+	if err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// copiesFrame reports whether the decoder for kind copies everything it
+// keeps, so the frame may be reused once decoding is done. Decoders that
+// produce strings reference the frame directly.
+func copiesFrame(kind MessageKind) bool {
+	switch kind {
+	case MessageKindBackendKeyData,
+		MessageKindBindComplete,
+		MessageKindCloseComplete,
+		MessageKindCopyData,
+		MessageKindCopyDone,
+		MessageKindCopyInResponse,
+		MessageKindCopyOutResponse,
+		MessageKindCopyBothResponse,
+		MessageKindDataRow,
+		MessageKindEmptyQueryResponse,
+		MessageKindFunctionCallResponse,
+		MessageKindNoData,
+		MessageKindParameterDescription,
+		MessageKindParseComplete,
+		MessageKindPortalSuspend,
+		MessageKindReadyForQuery:
+		return true
+	}
+	return false
+}
+
+// ParseBody decodes the body of a backend message of kind, the bytes
+// following its kind and length, as a lenient ReadBackend would. It suits
+// callers that already split the stream into messages, such as a custom
+// transport or a trace file. The body is copied.
+func ParseBody(kind MessageKind, body []byte) (Backend, error) {
+	if kind == MessageKindNone {
+		return nil, fmt.Errorf("%w: zero message kind", invalidFormat(pgio.ErrInvalidValue))
+	}
+
+	frame, err := NewFrame(kind, body)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newBackend(frame)
+	if err != nil {
+		return nil, decodeError(frame[0], nil, err)
+	}
+
+	err = m.UnmarshalBinary(frame)
+	if err != nil {
+		return nil, decodeError(frame[0], m, err)
+	}
+	return m, nil
+}
+
+// trackFormats follows the column formats of the current result. Only a
+// RowDescription sent for a portal or a simple query reports the formats
+// the rows use; one that answers a statement Describe reports text for
+// every column because the formats are chosen later, in Bind.
+func (x *MessageReader) trackFormats(m Backend) {
+	switch m := m.(type) {
+	case *MsgParameterDescription:
+		x.describingStatement = true
+	case *MsgRowDescription:
+		x.formats = nil
+		if !x.describingStatement {
+			x.formats = m.Formats
+		}
+		x.describingStatement = false
+	case *MsgNoData:
+		x.describingStatement = false
+	case *MsgCommandComplete, *MsgReadyForQuery:
+		x.formats = nil
+		x.describingStatement = false
+	}
+}
+
+func (x *MessageReader) parseBackend(frame []byte) (Backend, error) {
+	m, err := newBackend(frame)
+	if err != nil {
+		return nil, decodeError(frame[0], nil, err)
+	}
+
+	// ValidateFrame accepts the kinds of both directions, so a frontend
+	// kind on the backend stream is caught here.
+	if _, ok := m.(*MsgUnknown); ok && x.strict {
+		return nil, decodeError(frame[0], nil, invalidFormat(pgio.ErrUnknownMessageType))
+	}
+
+	if row, ok := m.(*MsgDataRow); ok && x.strict {
+		err = row.UnmarshalBinaryStrict(frame)
+	} else {
+		err = m.UnmarshalBinary(frame)
+	}
+	if err != nil {
+		return nil, decodeError(frame[0], m, err)
+	}
+	return m, nil
+}
+
+func newBackend(frame []byte) (Backend, error) {
+	kind, b, err := pgio.ShiftByte(frame)
+	if err != nil {
+		return nil, invalidFormat(err)
+	}
+
+	switch MessageKind(kind) {
+	case MessageKindAuthentication:
+		return newAuthentication(b)
+	case MessageKindBackendKeyData:
+		return &MsgBackendKeyData{}, nil
+	case MessageKindBindComplete:
+		return &MsgBindComplete{}, nil
+	case MessageKindCloseComplete:
+		return &MsgCloseComplete{}, nil
+	case MessageKindCommandComplete:
+		return &MsgCommandComplete{}, nil
+	case MessageKindCopyData:
+		return &MsgCopyData{}, nil
+	case MessageKindCopyDone:
+		return &MsgCopyDone{}, nil
+	case MessageKindCopyInResponse:
+		return &MsgCopyInResponse{}, nil
+	case MessageKindCopyOutResponse:
+		return &MsgCopyOutResponse{}, nil
+	case MessageKindCopyBothResponse:
+		return &MsgCopyBothResponse{}, nil
+	case MessageKindDataRow:
+		return &MsgDataRow{}, nil
+	case MessageKindEmptyQueryResponse:
+		return &MsgEmptyQueryResponse{}, nil
+	case MessageKindErrorResponse:
+		return &MsgErrorResponse{}, nil
+	case MessageKindFunctionCallResponse:
+		return &MsgFunctionCallResponse{}, nil
+	case MessageKindNegotiateProtocolVersion:
+		return &MsgNegotiateProtocolVersion{}, nil
+	case MessageKindNoData:
+		return &MsgNoData{}, nil
+	case MessageKindNoticeResponse:
+		return &MsgNoticeResponse{}, nil
+	case MessageKindNotificationResponse:
+		return &MsgNotificationResponse{}, nil
+	case MessageKindParameterDescription:
+		return &MsgParameterDescription{}, nil
+	case MessageKindParameterStatus:
+		return &MsgParameterStatus{}, nil
+	case MessageKindParseComplete:
+		return &MsgParseComplete{}, nil
+	case MessageKindPortalSuspend:
+		return &MsgPortalSuspended{}, nil
+	case MessageKindReadyForQuery:
+		return &MsgReadyForQuery{}, nil
+	case MessageKindRowDescription:
+		return &MsgRowDescription{}, nil
+	}
+	// Unknown backend messages are kept whole so they can be forwarded.
+	return &MsgUnknown{}, nil
+}
+
+func newAuthentication(b []byte) (Backend, error) {
+	b, err := shiftLength(b)
+	if err != nil {
+		return nil, invalidFormat(err)
+	}
+
+	authKind, _, err := pgio.ShiftInt32(b)
+	if err != nil {
+		return nil, invalidFormat(err)
+	}
+
+	switch AuthenticationKind(authKind) {
+	case AuthenticationKindOk:
+		return &MsgAuthenticationOk{}, nil
+	case AuthenticationKindKerberosV5:
+		return &MsgAuthenticationKerberosV5{}, nil
+	case AuthenticationKindClearTextPassword:
+		return &MsgAuthenticationCleartextPassword{}, nil
+	case AuthenticationKindMD5Password:
+		return &MsgAuthenticationMD5Password{}, nil
+	case AuthenticationKindGSS:
+		return &MsgAuthenticationGSS{}, nil
+	case AuthenticationKindGSSContinue:
+		return &MsgAuthenticationGSSContinue{}, nil
+	case AuthenticationKindSSPI:
+		return &MsgAuthenticationSSPI{}, nil
+	case AuthenticationKindSASL:
+		return &MsgAuthenticationSASL{}, nil
+	case AuthenticationKindSASLContinue:
+		return &MsgAuthenticationSASLContinue{}, nil
+	case AuthenticationKindSASLFinal:
+		return &MsgAuthenticationSASLFinal{}, nil
+	}
+	return nil, invalidFormat(pgio.ErrUnknownAuthType)
+}
+
+func (x *MessageReader) parseFrontend(frame []byte) (Frontend, error) {
+	m, err := newFrontend(frame)
+	if err != nil {
+		return nil, decodeError(frame[0], nil, err)
+	}
+
+	if _, ok := m.(*MsgUnknown); ok && x.strict {
+		return nil, decodeError(frame[0], nil, invalidFormat(pgio.ErrUnknownMessageType))
+	}
+
+	err = m.UnmarshalBinary(frame)
+	if err != nil {
+		return nil, decodeError(frame[0], m, err)
+	}
+	return m, nil
+}
+
+func newFrontend(frame []byte) (Frontend, error) {
+	kind, _, err := pgio.ShiftByte(frame)
+	if err != nil {
+		return nil, invalidFormat(err)
+	}
+
+	switch MessageKind(kind) {
+	case MessageKindBind:
+		return &MsgBind{}, nil
+	case MessageKindClose:
+		return &MsgClose{}, nil
+	case MessageKindCopyData:
+		return &MsgCopyData{}, nil
+	case MessageKindCopyDone:
+		return &MsgCopyDone{}, nil
+	case MessageKindCopyFail:
+		return &MsgCopyFail{}, nil
+	case MessageKindDescribe:
+		return &MsgDescribe{}, nil
+	case MessageKindExecute:
+		return &MsgExecute{}, nil
+	case MessageKindFlush:
+		return &MsgFlush{}, nil
+	case MessageKindFunctionCall:
+		return &MsgFunctionCall{}, nil
+	case MessageKindParse:
+		return &MsgParse{}, nil
+	case MessageKindPasswordMessage:
+		// 'p' is shared by every authentication response. Without knowing
+		// which authentication request it answers, it is read as a
+		// PasswordMessage; ReadAuthResponse picks the right type.
+		return &MsgPasswordMessage{}, nil
+	case MessageKindQuery:
+		return &MsgQuery{}, nil
+	case MessageKindSync:
+		return &MsgSync{}, nil
+	case MessageKindTerminate:
+		return &MsgTerminate{}, nil
+	}
+	// Unknown frontend messages are kept whole so they can be forwarded.
+	return &MsgUnknown{}, nil
+}
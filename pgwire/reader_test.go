@@ -0,0 +1,801 @@
+package pgwire_test
+
+import (
+	"bytes"
+	"fmt"
+	"gopsql/pgio"
+	"gopsql/pgwire"
+	"io"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestMessageReaderSkip(t *testing.T) {
+	t.Parallel()
+
+	unknown := pgio.NewBuffer(nil)
+	unknown.AppendByte('?')
+	unknown.AppendInt32(9)
+	unknown.AppendByte([]byte("hello")...)
+
+	var b []byte
+	b = append(b, appendMessages(t, &pgwire.MsgParseComplete{})...)
+	b = append(b, unknown.Bytes()...)
+	b = append(b, appendMessages(t, &pgwire.MsgCommandComplete{Tag: "SELECT 1"})...)
+
+	r := pgwire.NewMessageReader(bytes.NewReader(b))
+
+	m, err := r.ReadBackend()
+	require.NoError(t, err)
+	require.IsType(t, &pgwire.MsgParseComplete{}, m)
+
+	kind, err := r.Peek()
+	require.NoError(t, err)
+	require.Equal(t, pgwire.MessageKind('?'), kind)
+
+	kind, err = r.Skip()
+	require.NoError(t, err)
+	require.Equal(t, pgwire.MessageKind('?'), kind)
+
+	m, err = r.ReadBackend()
+	require.NoError(t, err)
+	require.Equal(t, &pgwire.MsgCommandComplete{Tag: "SELECT 1"}, m)
+}
+
+func TestMessageReaderSetEncoding(t *testing.T) {
+	t.Parallel()
+
+	latin1 := pgio.NewBuffer(nil)
+	latin1.AppendByte(byte(pgwire.MessageKindParameterStatus))
+	latin1.AppendInt32(24)
+	latin1.AppendString("application_name")
+	latin1.AppendByte('a', 0xe9, 0) // "aé" in LATIN1
+
+	t.Run("Passthrough", func(t *testing.T) {
+		r := pgwire.NewMessageReader(bytes.NewReader(latin1.Bytes()))
+
+		m, err := r.ReadBackend()
+		require.NoError(t, err)
+		require.Equal(t, "a\xe9", m.(*pgwire.MsgParameterStatus).Value)
+	})
+
+	t.Run("Latin1", func(t *testing.T) {
+		r := pgwire.NewMessageReader(bytes.NewReader(latin1.Bytes()))
+		r.SetEncoding(charmap.ISO8859_1)
+
+		m, err := r.ReadBackend()
+		require.NoError(t, err)
+		require.Equal(t, &pgwire.MsgParameterStatus{
+			Name:  "application_name",
+			Value: "aé",
+		}, m)
+	})
+
+	t.Run("DataRow", func(t *testing.T) {
+		b := appendMessages(t,
+			&pgwire.MsgRowDescription{
+				Names:     []string{"text", "bytea"},
+				Tables:    []int32{0, 0},
+				Columns:   []int16{0, 0},
+				DataTypes: []int32{25, 17},
+				Sizes:     []int16{-1, -1},
+				Modifiers: []int32{-1, -1},
+				Formats:   []int16{int16(pgwire.FormatKindText), int16(pgwire.FormatKindBinary)},
+			},
+			&pgwire.MsgDataRow{Columns: [][]byte{{0xe9}, {0xe9}}},
+		)
+
+		r := pgwire.NewMessageReader(bytes.NewReader(b))
+		r.SetEncoding(charmap.ISO8859_1)
+
+		_, err := r.ReadBackend()
+		require.NoError(t, err)
+
+		m, err := r.ReadBackend()
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("é"), {0xe9}}, m.(*pgwire.MsgDataRow).Columns)
+	})
+
+	t.Run("StatementDescribe", func(t *testing.T) {
+		desc := &pgwire.MsgRowDescription{
+			Names:     []string{"bytea"},
+			Tables:    []int32{0},
+			Columns:   []int16{0},
+			DataTypes: []int32{17},
+			Sizes:     []int16{-1},
+			Modifiers: []int32{-1},
+			Formats:   []int16{int16(pgwire.FormatKindText)},
+		}
+
+		// A statement Describe reports text, but the Bind that follows
+		// asks for binary results without describing the portal.
+		b := appendMessages(t,
+			&pgwire.MsgParseComplete{},
+			&pgwire.MsgParameterDescription{Parameters: []int32{}},
+			desc,
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+			&pgwire.MsgBindComplete{},
+			&pgwire.MsgDataRow{Columns: [][]byte{{0xe9}}},
+			&pgwire.MsgCommandComplete{Tag: "SELECT 1"},
+			desc,
+			&pgwire.MsgDataRow{Columns: [][]byte{{0xe9}}},
+			&pgwire.MsgCommandComplete{Tag: "SELECT 1"},
+			&pgwire.MsgDataRow{Columns: [][]byte{{0xe9}}},
+		)
+
+		r := pgwire.NewMessageReader(bytes.NewReader(b))
+		r.SetEncoding(charmap.ISO8859_1)
+
+		var rows [][][]byte
+
+		for {
+			m, err := r.ReadBackend()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+
+			if row, ok := m.(*pgwire.MsgDataRow); ok {
+				rows = append(rows, row.Columns)
+			}
+		}
+
+		// Only the row of the simple query, whose RowDescription reports
+		// its formats, is transcoded. The formats do not carry over to
+		// the next result.
+		require.Equal(t, [][][]byte{{{0xe9}}, {[]byte("é")}, {{0xe9}}}, rows)
+	})
+}
+
+func TestMessageReaderSmallBody(t *testing.T) {
+	t.Parallel()
+
+	large := bytes.Repeat([]byte("x"), 1024)
+
+	b := appendMessages(t,
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		&pgwire.MsgDataRow{Columns: [][]byte{[]byte("small")}},
+		&pgwire.MsgCommandComplete{Tag: "SELECT 1"},
+		&pgwire.MsgDataRow{Columns: [][]byte{large}},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindActive)},
+	)
+
+	r := pgwire.NewMessageReader(bytes.NewReader(b))
+
+	var got []pgwire.Backend
+
+	for range 5 {
+		m, err := r.ReadBackend()
+		require.NoError(t, err)
+		got = append(got, m)
+	}
+
+	// Earlier messages must be unaffected by later reads reusing the
+	// reader's small buffer.
+	require.Equal(t, []pgwire.Backend{
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		&pgwire.MsgDataRow{Columns: [][]byte{[]byte("small")}},
+		&pgwire.MsgCommandComplete{Tag: "SELECT 1"},
+		&pgwire.MsgDataRow{Columns: [][]byte{large}},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindActive)},
+	}, got)
+}
+
+func BenchmarkMessageReaderReadyForQuery(b *testing.B) {
+	frame, err := (&pgwire.MsgReadyForQuery{
+		TxStatus: byte(pgwire.TransactionStatusKindIdle),
+	}).AppendBinary(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	stream := bytes.Repeat(frame, 1024)
+	src := bytes.NewReader(stream)
+	r := pgwire.NewMessageReader(src)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if src.Len() == 0 {
+			src.Reset(stream)
+		}
+
+		_, err := r.ReadBackend()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestMessageReaderSetInternLimit(t *testing.T) {
+	t.Parallel()
+
+	description := &pgwire.MsgRowDescription{
+		Names:     []string{"id", "name"},
+		Tables:    []int32{0, 0},
+		Columns:   []int16{0, 0},
+		DataTypes: []int32{23, 25},
+		Sizes:     []int16{4, -1},
+		Modifiers: []int32{-1, -1},
+		Formats:   []int16{0, 0},
+	}
+
+	b := appendMessages(t, description, description, description)
+
+	r := pgwire.NewMessageReader(bytes.NewReader(b))
+	r.SetInternLimit(1)
+
+	var got []*pgwire.MsgRowDescription
+
+	for range 3 {
+		m, err := r.ReadBackend()
+		require.NoError(t, err)
+		got = append(got, m.(*pgwire.MsgRowDescription))
+	}
+
+	for _, m := range got {
+		require.Equal(t, description.Names, m.Names)
+	}
+
+	// Only "id" fits in the cache.
+	require.Same(t, unsafe.StringData(got[0].Names[0]), unsafe.StringData(got[1].Names[0]))
+	require.Same(t, unsafe.StringData(got[0].Names[0]), unsafe.StringData(got[2].Names[0]))
+	require.NotSame(t, unsafe.StringData(got[0].Names[1]), unsafe.StringData(got[1].Names[1]))
+}
+
+func BenchmarkMessageReaderRowDescription(b *testing.B) {
+	description := &pgwire.MsgRowDescription{
+		Names:     []string{"id", "name", "created_at", "updated_at"},
+		Tables:    []int32{16384, 16384, 16384, 16384},
+		Columns:   []int16{1, 2, 3, 4},
+		DataTypes: []int32{23, 25, 1184, 1184},
+		Sizes:     []int16{4, -1, 8, 8},
+		Modifiers: []int32{-1, -1, -1, -1},
+		Formats:   []int16{0, 0, 0, 0},
+	}
+
+	frame, err := description.AppendBinary(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	stream := bytes.Repeat(frame, 1024)
+
+	for _, limit := range []int{0, 64} {
+		b.Run(fmt.Sprintf("Limit%d", limit), func(b *testing.B) {
+			src := bytes.NewReader(stream)
+			r := pgwire.NewMessageReader(src)
+			r.SetInternLimit(limit)
+
+			b.ReportAllocs()
+
+			for b.Loop() {
+				if src.Len() == 0 {
+					src.Reset(stream)
+				}
+
+				_, err := r.ReadBackend()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageReaderReadStartup(t *testing.T) {
+	t.Parallel()
+
+	startup := &pgwire.MsgStartupMessage{
+		ProtocolVersion: pgwire.ProtocolVersion(pgwire.ProtocolVersion3_0),
+		Parameters: []pgwire.StartupParameter{
+			{Name: pgwire.ParamUser, Value: "postgres"},
+		},
+	}
+
+	tests := map[string]pgwire.Frontend{
+		"SSLRequest":     &pgwire.MsgSSLRequest{},
+		"GSSENCRequest":  &pgwire.MsgGSSENCRequest{},
+		"CancelRequest":  &pgwire.MsgCancelRequest{ProcessID: 42, SecretKey: []byte{1, 2, 3, 4}},
+		"StartupMessage": startup,
+	}
+
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := newScript(t, want)
+
+			got, err := pgwire.NewServerConn(s).ReceiveStartup()
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+
+	t.Run("SSLThenStartup", func(t *testing.T) {
+		t.Parallel()
+
+		r := pgwire.NewMessageReader(bytes.NewReader(appendMessages(t, &pgwire.MsgSSLRequest{}, startup)))
+
+		m, err := r.ReadStartup()
+		require.NoError(t, err)
+		require.Equal(t, &pgwire.MsgSSLRequest{}, m)
+
+		m, err = r.ReadStartup()
+		require.NoError(t, err)
+		require.Equal(t, startup, m)
+	})
+
+	t.Run("Short", func(t *testing.T) {
+		t.Parallel()
+
+		r := pgwire.NewMessageReader(bytes.NewReader([]byte{0, 0, 0, 4}))
+
+		_, err := r.ReadStartup()
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+	})
+
+	t.Run("Long", func(t *testing.T) {
+		t.Parallel()
+
+		r := pgwire.NewMessageReader(bytes.NewReader([]byte{0, 1, 0, 0}))
+
+		_, err := r.ReadStartup()
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueOverflow)
+	})
+
+	t.Run("Client", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := pgwire.NewConn(newScript(t, startup)).ReceiveStartup()
+		require.ErrorIs(t, err, pgwire.ErrWrongRole)
+	})
+}
+
+func TestMessageReaderReadAuthResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		req  pgwire.Backend
+		resp pgwire.Frontend
+	}{
+		"Cleartext": {
+			req:  &pgwire.MsgAuthenticationCleartextPassword{},
+			resp: &pgwire.MsgPasswordMessage{Password: "secret"},
+		},
+		"MD5": {
+			req:  pgwire.NewAuthenticationMD5PasswordSalt([4]byte{1, 2, 3, 4}),
+			resp: pgwire.MD5Password("postgres", "secret", [4]byte{1, 2, 3, 4}),
+		},
+		"SASL": {
+			req:  &pgwire.MsgAuthenticationSASL{Mechanisms: []string{"SCRAM-SHA-256"}},
+			resp: &pgwire.MsgSASLInitialResponse{Name: "SCRAM-SHA-256", Response: []byte("n,,n=,r=nonce")},
+		},
+		"SASLContinue": {
+			req:  &pgwire.MsgAuthenticationSASLContinue{Data: []byte("r=nonce")},
+			resp: &pgwire.MsgSASLResponse{Data: []byte("c=biws,r=nonce,p=proof")},
+		},
+		"GSS": {
+			req:  &pgwire.MsgAuthenticationGSS{},
+			resp: &pgwire.MsgGSSResponse{Data: []byte{1, 2, 3}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c := pgwire.NewServerConn(newScript(t, tt.resp))
+
+			got, err := c.ReceiveAuthResponse(tt.req)
+			require.NoError(t, err)
+			require.Equal(t, tt.resp, got)
+		})
+	}
+
+	t.Run("NoResponse", func(t *testing.T) {
+		t.Parallel()
+
+		c := pgwire.NewServerConn(newScript(t, &pgwire.MsgPasswordMessage{Password: "secret"}))
+
+		_, err := c.ReceiveAuthResponse(&pgwire.MsgAuthenticationOk{})
+		require.ErrorIs(t, err, pgwire.ErrUnexpectedKind)
+	})
+}
+
+func TestMessageReaderDirection(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		frontend pgwire.Frontend
+		backend  pgwire.Backend
+	}{
+		{"Sync", &pgwire.MsgSync{}, &pgwire.MsgParameterStatus{Name: "TimeZone", Value: "UTC"}},
+		{"Flush", &pgwire.MsgFlush{}, &pgwire.MsgCopyOutResponse{Format: 0, Columns: []int16{0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			frontend := appendMessages(t, tt.frontend)
+			backend := appendMessages(t, tt.backend)
+			require.Len(t, frontend, 5)
+			require.Equal(t, frontend[0], backend[0])
+
+			m, err := pgwire.NewMessageReader(bytes.NewReader(frontend)).ReadFrontend()
+			require.NoError(t, err)
+			require.Equal(t, tt.frontend, m)
+
+			_, err = pgwire.NewMessageReader(bytes.NewReader(backend)).ReadFrontend()
+			require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+
+			b, err := pgwire.NewMessageReader(bytes.NewReader(backend)).ReadBackend()
+			require.NoError(t, err)
+			require.Equal(t, tt.backend, b)
+
+			_, err = pgwire.NewMessageReader(bytes.NewReader(frontend)).ReadBackend()
+			require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		})
+	}
+}
+
+func TestMessageReaderDecodeError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Backend", func(t *testing.T) {
+		t.Parallel()
+
+		b := []byte{byte(pgwire.MessageKindDataRow), 0, 0, 0, 6, 0x7f, 0xff}
+
+		_, err := pgwire.NewMessageReader(bytes.NewReader(b)).ReadBackend()
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+		require.ErrorContains(t, err, "*pgwire.MsgDataRow (kind 'D')")
+	})
+
+	t.Run("Frontend", func(t *testing.T) {
+		t.Parallel()
+
+		b := appendMessages(t, &pgwire.MsgClose{ObjectKind: pgwire.ObjectKindPortal})
+		b[5] = 'X'
+
+		_, err := pgwire.NewMessageReader(bytes.NewReader(b)).ReadFrontend()
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrInvalidValue)
+		require.ErrorContains(t, err, "*pgwire.MsgClose (kind 'C')")
+	})
+
+	t.Run("UnknownKind", func(t *testing.T) {
+		t.Parallel()
+
+		b := []byte{'?', 0, 0, 0, 4}
+
+		r := pgwire.NewMessageReader(bytes.NewReader(b))
+		r.SetStrict(true)
+
+		_, err := r.ReadBackend()
+		require.ErrorIs(t, err, pgio.ErrUnknownMessageType)
+		require.ErrorContains(t, err, "kind '?'")
+	})
+
+	t.Run("WrongDirection", func(t *testing.T) {
+		t.Parallel()
+
+		r := pgwire.NewMessageReader(bytes.NewReader(appendMessages(t, &pgwire.MsgTerminate{})))
+		r.SetStrict(true)
+
+		_, err := r.ReadBackend()
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrUnknownMessageType)
+
+		r = pgwire.NewMessageReader(bytes.NewReader(appendMessages(t, &pgwire.MsgBindComplete{})))
+		r.SetStrict(true)
+
+		_, err = r.ReadFrontend()
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrUnknownMessageType)
+
+		m, err := pgwire.NewMessageReader(bytes.NewReader(appendMessages(t, &pgwire.MsgTerminate{}))).ReadBackend()
+		require.NoError(t, err)
+		require.Equal(t, &pgwire.MsgUnknown{MessageKind: pgwire.MessageKindTerminate, Data: []byte{}}, m)
+	})
+}
+
+func TestValidateFrame(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, pgwire.ValidateFrame(byte(pgwire.MessageKindDataRow), 4))
+	require.NoError(t, pgwire.ValidateFrame(byte(pgwire.MessageKindTerminate), pgwire.MaxMessageLength))
+
+	err := pgwire.ValidateFrame('?', 4)
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	require.ErrorIs(t, err, pgio.ErrUnknownMessageType)
+
+	err = pgwire.ValidateFrame(byte(pgwire.MessageKindQuery), 3)
+	require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+
+	err = pgwire.ValidateFrame(byte(pgwire.MessageKindQuery), -1)
+	require.ErrorIs(t, err, pgio.ErrValueUnderflow)
+
+	err = pgwire.ValidateFrame(byte(pgwire.MessageKindQuery), pgwire.MaxMessageLength+1)
+	require.ErrorIs(t, err, pgio.ErrValueOverflow)
+}
+
+func TestMessageReaderValidatesFrame(t *testing.T) {
+	t.Parallel()
+
+	unknown := []byte{'?', 0, 0, 0, 4}
+
+	t.Run("Lenient", func(t *testing.T) {
+		t.Parallel()
+
+		kind, err := pgwire.NewMessageReader(bytes.NewReader(unknown)).Skip()
+		require.NoError(t, err)
+		require.Equal(t, pgwire.MessageKind('?'), kind)
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		t.Parallel()
+
+		r := pgwire.NewMessageReader(bytes.NewReader(unknown))
+		r.SetStrict(true)
+
+		_, err := r.Skip()
+		require.ErrorIs(t, err, pgio.ErrUnknownMessageType)
+	})
+
+	t.Run("TooLong", func(t *testing.T) {
+		t.Parallel()
+
+		// Rejected from the header alone; no body follows.
+		b := []byte{byte(pgwire.MessageKindDataRow), 0x7f, 0xff, 0xff, 0xff}
+
+		_, err := pgwire.NewMessageReader(bytes.NewReader(b)).ReadBackend()
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.ErrorIs(t, err, pgio.ErrValueOverflow)
+	})
+}
+
+func TestMessageReaderSetBudget(t *testing.T) {
+	t.Parallel()
+
+	// Each ReadyForQuery frame is 6 bytes.
+	b := appendMessages(t,
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	r := pgwire.NewMessageReader(bytes.NewReader(b))
+	r.SetBudget(15)
+
+	_, err := r.ReadBackend()
+	require.NoError(t, err)
+	require.Equal(t, int64(6), r.BytesRead())
+
+	_, err = r.ReadBackend()
+	require.NoError(t, err)
+	require.Equal(t, int64(12), r.BytesRead())
+
+	_, err = r.ReadBackend()
+	require.ErrorIs(t, err, pgwire.ErrBudgetExceeded)
+	require.Equal(t, int64(12), r.BytesRead())
+}
+
+func TestMessageReaderBytesRead(t *testing.T) {
+	t.Parallel()
+
+	b := appendMessages(t,
+		&pgwire.MsgStartupMessage{ProtocolVersion: pgwire.ProtocolVersion(pgwire.ProtocolVersion3_0)},
+		&pgwire.MsgQuery{Value: "SELECT 1"},
+	)
+
+	r := pgwire.NewMessageReader(bytes.NewReader(b))
+
+	_, err := r.ReadStartup()
+	require.NoError(t, err)
+
+	_, err = r.ReadFrontend()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(b)), r.BytesRead())
+}
+
+func TestMessageKind(t *testing.T) {
+	t.Parallel()
+
+	b := appendMessages(t,
+		&pgwire.MsgAuthenticationOk{},
+		&pgwire.MsgParameterStatus{Name: "TimeZone", Value: "UTC"},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+	r := pgwire.NewMessageReader(bytes.NewReader(b))
+
+	var kinds []pgwire.MessageKind
+
+	for range 3 {
+		m, err := r.ReadBackend()
+		require.NoError(t, err)
+		kinds = append(kinds, m.Kind())
+	}
+
+	require.Equal(t, []pgwire.MessageKind{
+		pgwire.MessageKindAuthentication,
+		pgwire.MessageKindParameterStatus,
+		pgwire.MessageKindReadyForQuery,
+	}, kinds)
+
+	require.Equal(t, pgwire.MessageKindNone, (&pgwire.MsgStartupMessage{}).Kind())
+	require.Equal(t, pgwire.MessageKindPortalSuspend, (&pgwire.MsgPortalSuspended{}).Kind())
+	require.Equal(t, pgwire.MessageKind('@'), (&pgwire.MsgUnknown{MessageKind: '@'}).Kind())
+}
+
+// chunkReader returns its data in reads of at most size bytes, so that
+// frames arrive split at arbitrary points.
+type chunkReader struct {
+	data []byte
+	size int
+}
+
+func (x *chunkReader) Read(p []byte) (int, error) {
+	if len(x.data) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p[:min(len(p), x.size)], x.data)
+	x.data = x.data[n:]
+	return n, nil
+}
+
+func TestMessageReaderBufferBoundary(t *testing.T) {
+	t.Parallel()
+
+	// bufio.Reader's default buffer size.
+	const bufferSize = 4096
+
+	row := &pgwire.MsgDataRow{Columns: [][]byte{
+		bytes.Repeat([]byte("a"), 3000),
+		nil,
+		bytes.Repeat([]byte("b"), 3000),
+	}}
+	ready := &pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)}
+
+	// Place the DataRow so that its header ends before, straddles, or
+	// starts after the end of the first buffered chunk.
+	for _, offset := range []int{-6, -5, -3, -1, 0, 1} {
+		padding := &pgwire.MsgCopyData{Data: make([]byte, bufferSize+offset-5)}
+		stream := appendMessages(t, padding, row, ready)
+
+		for _, size := range []int{1, 3, 7, bufferSize - 1, bufferSize, bufferSize + 1, len(stream)} {
+			t.Run(fmt.Sprintf("Offset%d/Chunk%d", offset, size), func(t *testing.T) {
+				t.Parallel()
+
+				r := pgwire.NewMessageReader(&chunkReader{data: stream, size: size})
+
+				for _, want := range []pgwire.Backend{padding, row, ready} {
+					got, err := r.ReadBackend()
+					require.NoError(t, err)
+					require.True(t, pgwire.Equal(want, got), pgwire.Diff(want, got))
+				}
+
+				_, err := r.ReadBackend()
+				require.ErrorIs(t, err, io.EOF)
+
+				r = pgwire.NewMessageReader(&chunkReader{data: stream, size: size})
+
+				var frames []byte
+				for range 3 {
+					frame, err := r.Next()
+					require.NoError(t, err)
+					frames = append(frames, frame...)
+				}
+				require.Equal(t, stream, frames)
+			})
+		}
+	}
+}
+
+func TestParseBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []pgwire.Backend{
+		&pgwire.MsgAuthenticationOk{},
+		&pgwire.MsgAuthenticationMD5Password{Salt: [4]byte{1, 2, 3, 4}},
+		&pgwire.MsgAuthenticationSASL{Mechanisms: []string{"SCRAM-SHA-256"}},
+		&pgwire.MsgAuthenticationSASLContinue{Data: []byte("r=abc")},
+		&pgwire.MsgAuthenticationSASLFinal{Data: []byte("v=xyz")},
+		&pgwire.MsgBackendKeyData{ProcessID: 7, SecretKey: []byte{1, 2, 3, 4}},
+		&pgwire.MsgBindComplete{},
+		&pgwire.MsgCloseComplete{},
+		&pgwire.MsgCommandComplete{Tag: "SELECT 1"},
+		&pgwire.MsgCopyData{Data: []byte("1\n")},
+		&pgwire.MsgCopyDone{},
+		&pgwire.MsgCopyInResponse{Columns: []int16{0}},
+		&pgwire.MsgCopyOutResponse{Columns: []int16{0}},
+		&pgwire.MsgCopyBothResponse{Columns: []int16{}},
+		&pgwire.MsgDataRow{Columns: [][]byte{[]byte("1"), nil}},
+		&pgwire.MsgEmptyQueryResponse{},
+		&pgwire.MsgErrorResponse{Fields: []byte("SM"), Values: []string{"ERROR", "boom"}},
+		&pgwire.MsgFunctionCallResponse{Result: []byte("42")},
+		&pgwire.MsgNegotiateProtocolVersion{MinorVersionSupported: 0, UnrecognizedOptions: []string{"_pq_.x"}},
+		&pgwire.MsgNoData{},
+		&pgwire.MsgNoticeResponse{Fields: []byte("SM"), Values: []string{"NOTICE", "hello"}},
+		&pgwire.MsgNotificationResponse{ProcessID: 7, Channel: "c", Payload: "p"},
+		&pgwire.MsgParameterDescription{Parameters: []int32{23}},
+		&pgwire.MsgParameterStatus{Name: "TimeZone", Value: "UTC"},
+		&pgwire.MsgParseComplete{},
+		&pgwire.MsgPortalSuspended{},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		pgwire.NewRowDescription([]pgwire.ColumnDescription{{Name: "id", DataType: 23, Size: 4, Modifier: -1}}),
+	}
+
+	for _, want := range tests {
+		t.Run(pgwire.Summary(want), func(t *testing.T) {
+			t.Parallel()
+
+			frame := pgwire.Frame(appendMessages(t, want))
+
+			got, err := pgwire.ParseBody(frame.Kind(), frame.Body())
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+
+	t.Run("UnknownAuthentication", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := pgwire.ParseBody(pgwire.MessageKindAuthentication, []byte{0, 0, 0, 99})
+		require.ErrorIs(t, err, pgio.ErrUnknownAuthType)
+	})
+
+	t.Run("ZeroKind", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := pgwire.ParseBody(pgwire.MessageKindNone, nil)
+		require.ErrorIs(t, err, pgio.ErrInvalidValue)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := pgwire.ParseBody(pgwire.MessageKindBackendKeyData, []byte{0, 0})
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	})
+}
+
+func TestMessageReaderZeroKind(t *testing.T) {
+	t.Parallel()
+
+	frame := []byte{0, 0, 0, 0, 4}
+
+	read := map[string]func(*pgwire.MessageReader) error{
+		"ReadBackend": func(r *pgwire.MessageReader) error {
+			_, err := r.ReadBackend()
+			return err
+		},
+		"ReadFrontend": func(r *pgwire.MessageReader) error {
+			_, err := r.ReadFrontend()
+			return err
+		},
+		"Next": func(r *pgwire.MessageReader) error {
+			_, err := r.Next()
+			return err
+		},
+	}
+
+	for name, fn := range read {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := fn(pgwire.NewMessageReader(bytes.NewReader(frame)))
+			require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+			require.ErrorIs(t, err, pgio.ErrInvalidValue)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package pgwire
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// IdentifySystem is the result of the IDENTIFY_SYSTEM replication command.
+type IdentifySystem struct {
+	SystemID string
+	Timeline int32
+	XLogPos  string
+
+	// DBName is empty when the replication connection is not connected to
+	// a database.
+	DBName string
+}
+
+// ParseIdentifySystem decodes the single text format row returned by
+// IDENTIFY_SYSTEM.
+func ParseIdentifySystem(desc *MsgRowDescription, row *MsgDataRow) (*IdentifySystem, error) {
+	if len(desc.Names) != len(row.Columns) {
+		return nil, invalidFormat(fmt.Errorf("row has %d columns, description has %d",
+			len(row.Columns), len(desc.Names)))
+	}
+
+	column := func(name string) ([]byte, error) {
+		for i, n := range desc.Names {
+			if n == name {
+				return row.Columns[i], nil
+			}
+		}
+		return nil, invalidFormat(fmt.Errorf("missing column %q", name))
+	}
+
+	systemID, err := column("systemid")
+	if err != nil {
+		return nil, err
+	}
+
+	timeline, err := column("timeline")
+	if err != nil {
+		return nil, err
+	}
+
+	xlogPos, err := column("xlogpos")
+	if err != nil {
+		return nil, err
+	}
+
+	dbName, err := column("dbname")
+	if err != nil {
+		return nil, err
+	}
+
+	tli, err := strconv.ParseInt(string(timeline), 10, 32)
+	if err != nil {
+		return nil, invalidFormat(err)
+	}
+
+	return &IdentifySystem{
+		SystemID: string(systemID),
+		Timeline: int32(tli),
+		XLogPos:  string(xlogPos),
+		DBName:   string(dbName),
+	}, nil
+}
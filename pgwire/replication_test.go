@@ -0,0 +1,64 @@
+package pgwire_test
+
+import (
+	"gopsql/pgwire"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func identifySystemDescription() *pgwire.MsgRowDescription {
+	return &pgwire.MsgRowDescription{
+		Names:     []string{"systemid", "timeline", "xlogpos", "dbname"},
+		Tables:    []int32{0, 0, 0, 0},
+		Columns:   []int16{0, 0, 0, 0},
+		DataTypes: []int32{25, 23, 25, 25},
+		Sizes:     []int16{-1, 4, -1, -1},
+		Modifiers: []int32{-1, -1, -1, -1},
+		Formats:   []int16{0, 0, 0, 0},
+	}
+}
+
+func TestParseIdentifySystem(t *testing.T) {
+	t.Parallel()
+
+	row := &pgwire.MsgDataRow{Columns: [][]byte{
+		[]byte("7350423629383512345"),
+		[]byte("1"),
+		[]byte("0/16B3748"),
+		nil,
+	}}
+
+	got, err := pgwire.ParseIdentifySystem(identifySystemDescription(), row)
+	require.NoError(t, err)
+	require.Equal(t, &pgwire.IdentifySystem{
+		SystemID: "7350423629383512345",
+		Timeline: 1,
+		XLogPos:  "0/16B3748",
+	}, got)
+}
+
+func TestParseIdentifySystemInvalid(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Timeline", func(t *testing.T) {
+		row := &pgwire.MsgDataRow{Columns: [][]byte{
+			[]byte("7350423629383512345"),
+			[]byte("one"),
+			[]byte("0/16B3748"),
+			[]byte("postgres"),
+		}}
+
+		_, err := pgwire.ParseIdentifySystem(identifySystemDescription(), row)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	})
+
+	t.Run("Columns", func(t *testing.T) {
+		row := &pgwire.MsgDataRow{Columns: [][]byte{
+			[]byte("7350423629383512345"),
+		}}
+
+		_, err := pgwire.ParseIdentifySystem(identifySystemDescription(), row)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+	})
+}
@@ -0,0 +1,55 @@
+package pgwire
+
+type QueryResult struct {
+	// Description is nil for statements that return no rows.
+	Description *MsgRowDescription
+	Rows        [][][]byte
+	Tag         string
+}
+
+type resultCollector struct {
+	last    *QueryResult
+	current *QueryResult
+	err     error
+}
+
+// add records m and reports whether the response is complete.
+func (x *resultCollector) add(m Backend) bool {
+	switch m := m.(type) {
+	case *MsgRowDescription:
+		x.current = &QueryResult{Description: m}
+	case *MsgDataRow:
+		if x.current == nil {
+			x.current = &QueryResult{}
+		}
+		x.current.Rows = append(x.current.Rows, m.Columns)
+	case *MsgCommandComplete:
+		if x.current == nil {
+			x.current = &QueryResult{}
+		}
+		x.current.Tag = m.Tag
+		x.last = x.current
+		x.current = nil
+	case *MsgEmptyQueryResponse:
+		x.last = &QueryResult{}
+		x.current = nil
+	case *MsgErrorResponse:
+		if x.err == nil {
+			x.err = newPgError(m)
+		}
+	case *MsgReadyForQuery:
+		return true
+	}
+	return false
+}
+
+func (x *resultCollector) result() (*QueryResult, error) {
+	if x.err != nil {
+		return nil, x.err
+	}
+
+	if x.last == nil {
+		return &QueryResult{}, nil
+	}
+	return x.last, nil
+}
@@ -0,0 +1,232 @@
+package pgwire
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Data type OIDs of the built-in types Row understands.
+const (
+	oidBool        = 16
+	oidBytea       = 17
+	oidChar        = 18
+	oidName        = 19
+	oidInt8        = 20
+	oidInt2        = 21
+	oidInt4        = 23
+	oidText        = 25
+	oidOID         = 26
+	oidJSON        = 114
+	oidUnknown     = 705
+	oidBPChar      = 1042
+	oidVarchar     = 1043
+	oidDate        = 1082
+	oidTimestamp   = 1114
+	oidTimestamptz = 1184
+)
+
+// postgresEpoch is the zero point of binary dates and timestamps.
+var postgresEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Row interprets the columns of a DataRow using the types and formats of
+// the RowDescription that preceded it. Each getter reports NULL through the
+// Valid field of its result, and fails with ErrUnsupportedType when the
+// column's type cannot be read as the requested Go type.
+type Row struct {
+	desc    *MsgRowDescription
+	columns [][]byte
+}
+
+func NewRow(desc *MsgRowDescription, row *MsgDataRow) Row {
+	return Row{desc: desc, columns: row.Columns}
+}
+
+// column returns the value of column i along with its type and whether it
+// is in the binary format.
+func (x Row) column(i int) ([]byte, int32, bool, error) {
+	if i < 0 || i >= len(x.columns) || i >= len(x.desc.DataTypes) || i >= len(x.desc.Formats) {
+		return nil, 0, false, fmt.Errorf("%w: column %d out of range", ErrInvalidFormat, i)
+	}
+	return x.columns[i], x.desc.DataTypes[i], FormatKind(x.desc.Formats[i]) == FormatKindBinary, nil
+}
+
+func (x Row) unsupported(i int, dataType int32, isBinary bool, as string) error {
+	format := FormatKindText
+	if isBinary {
+		format = FormatKindBinary
+	}
+
+	name := ""
+	if i < len(x.desc.Names) {
+		name = x.desc.Names[i]
+	}
+	return fmt.Errorf("%w: column %d (%q) of type OID %d in %s format cannot be read as %s",
+		ErrUnsupportedType, i, name, dataType, format, as)
+}
+
+// GetString returns column i as text. Any type can be read in the text
+// format; in the binary format only the character types can.
+func (x Row) GetString(i int) (sql.NullString, error) {
+	value, dataType, isBinary, err := x.column(i)
+	if err != nil || value == nil {
+		return sql.NullString{}, err
+	}
+
+	if isBinary {
+		switch dataType {
+		case oidText, oidVarchar, oidBPChar, oidName, oidChar, oidJSON, oidUnknown:
+		default:
+			return sql.NullString{}, x.unsupported(i, dataType, isBinary, "a string")
+		}
+	}
+	return sql.NullString{String: string(value), Valid: true}, nil
+}
+
+// GetBytes returns column i as it was sent, except for a bytea in the text
+// format, whose hex encoding is decoded. A NULL column is nil.
+func (x Row) GetBytes(i int) ([]byte, error) {
+	value, dataType, isBinary, err := x.column(i)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	if dataType != oidBytea || isBinary {
+		return value, nil
+	}
+
+	s, ok := strings.CutPrefix(string(value), `\x`)
+	if !ok {
+		return nil, fmt.Errorf("%w: column %d: bytea is not in the hex format", ErrInvalidFormat, i)
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, invalidFormat(err)
+	}
+	return b, nil
+}
+
+// GetInt64 returns column i, which must be an int2, int4, int8 or oid.
+func (x Row) GetInt64(i int) (sql.NullInt64, error) {
+	value, dataType, isBinary, err := x.column(i)
+	if err != nil || value == nil {
+		return sql.NullInt64{}, err
+	}
+
+	var n int64
+
+	switch {
+	case dataType != oidInt2 && dataType != oidInt4 && dataType != oidInt8 && dataType != oidOID:
+		return sql.NullInt64{}, x.unsupported(i, dataType, isBinary, "an int64")
+	case !isBinary:
+		n, err = strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return sql.NullInt64{}, invalidFormat(err)
+		}
+	case dataType == oidOID && len(value) == 4:
+		n = int64(binary.BigEndian.Uint32(value))
+	case len(value) == 2:
+		n = int64(int16(binary.BigEndian.Uint16(value)))
+	case len(value) == 4:
+		n = int64(int32(binary.BigEndian.Uint32(value)))
+	case len(value) == 8:
+		n = int64(binary.BigEndian.Uint64(value))
+	default:
+		return sql.NullInt64{}, fmt.Errorf("%w: column %d: %d byte integer", ErrInvalidFormat, i, len(value))
+	}
+	return sql.NullInt64{Int64: n, Valid: true}, nil
+}
+
+// GetBool returns column i, which must be a bool.
+func (x Row) GetBool(i int) (sql.NullBool, error) {
+	value, dataType, isBinary, err := x.column(i)
+	if err != nil || value == nil {
+		return sql.NullBool{}, err
+	}
+
+	if dataType != oidBool {
+		return sql.NullBool{}, x.unsupported(i, dataType, isBinary, "a bool")
+	}
+
+	if !isBinary {
+		b, err := ParseBoolText(string(value))
+		if err != nil {
+			return sql.NullBool{}, err
+		}
+		return sql.NullBool{Bool: b, Valid: true}, nil
+	}
+
+	if len(value) != 1 {
+		return sql.NullBool{}, fmt.Errorf("%w: column %d: %d byte bool", ErrInvalidFormat, i, len(value))
+	}
+	return sql.NullBool{Bool: value[0] != 0, Valid: true}, nil
+}
+
+// GetTime returns column i, which must be a date, timestamp or timestamptz.
+// Dates are midnight UTC and timestamps without time zone are read as UTC.
+// The infinite values are not supported.
+func (x Row) GetTime(i int) (sql.NullTime, error) {
+	value, dataType, isBinary, err := x.column(i)
+	if err != nil || value == nil {
+		return sql.NullTime{}, err
+	}
+
+	var t time.Time
+
+	switch {
+	case dataType != oidDate && dataType != oidTimestamp && dataType != oidTimestamptz:
+		return sql.NullTime{}, x.unsupported(i, dataType, isBinary, "a time")
+	case !isBinary && dataType == oidDate:
+		t, err = ParseDateText(string(value))
+	case !isBinary && dataType == oidTimestamptz:
+		t, err = ParseTimestamptzText(string(value))
+	case !isBinary:
+		t, err = parseTimestampText(string(value))
+	case dataType == oidDate && len(value) == 4:
+		days := int32(binary.BigEndian.Uint32(value))
+		if days == math.MaxInt32 || days == math.MinInt32 {
+			return sql.NullTime{}, fmt.Errorf("%w: column %d: infinite date", ErrInvalidFormat, i)
+		}
+		t = postgresEpoch.AddDate(0, 0, int(days))
+	case dataType != oidDate && len(value) == 8:
+		micros := int64(binary.BigEndian.Uint64(value))
+		if micros == math.MaxInt64 || micros == math.MinInt64 {
+			return sql.NullTime{}, fmt.Errorf("%w: column %d: infinite timestamp", ErrInvalidFormat, i)
+		}
+		t = timestampFromMicros(micros)
+	default:
+		return sql.NullTime{}, fmt.Errorf("%w: column %d: %d byte time", ErrInvalidFormat, i, len(value))
+	}
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
+
+// parseTimestampText parses a timestamp without time zone in the ISO text
+// format, such as "2006-01-02 15:04:05.999999", as UTC.
+func parseTimestampText(s string) (time.Time, error) {
+	t, err := parseEra(time.DateTime, s)
+	if err != nil {
+		return time.Time{}, invalidFormat(err)
+	}
+	return t, nil
+}
+
+// timestampFromMicros converts a binary timestamp, microseconds since
+// postgresEpoch, without going through time.Duration, which only spans
+// about 292 years.
+func timestampFromMicros(micros int64) time.Time {
+	sec, usec := micros/1e6, micros%1e6
+	if usec < 0 {
+		sec--
+		usec += 1e6
+	}
+	return time.Unix(postgresEpoch.Unix()+sec, usec*1e3).UTC()
+}
@@ -0,0 +1,191 @@
+package pgwire_test
+
+import (
+	"encoding/binary"
+	"gopsql/pgwire"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func rowDescription(names []string, dataTypes []int32, formats []int16) *pgwire.MsgRowDescription {
+	n := len(names)
+	return &pgwire.MsgRowDescription{
+		Names:     names,
+		Tables:    make([]int32, n),
+		Columns:   make([]int16, n),
+		DataTypes: dataTypes,
+		Sizes:     make([]int16, n),
+		Modifiers: make([]int32, n),
+		Formats:   formats,
+	}
+}
+
+func TestRowText(t *testing.T) {
+	t.Parallel()
+
+	desc := rowDescription(
+		[]string{"name", "n", "ok", "at", "day", "local", "data", "missing"},
+		[]int32{25, 23, 16, 1184, 1082, 1114, 17, 20},
+		make([]int16, 8),
+	)
+	row := pgwire.NewRow(desc, &pgwire.MsgDataRow{Columns: [][]byte{
+		[]byte("alice"),
+		[]byte("-42"),
+		[]byte("t"),
+		[]byte("2024-02-29 12:30:00+02"),
+		[]byte("2024-02-29"),
+		[]byte("2024-02-29 12:30:00.5"),
+		[]byte(`\x0102ff`),
+		nil,
+	}})
+
+	s, err := row.GetString(0)
+	require.NoError(t, err)
+	require.Equal(t, "alice", s.String)
+	require.True(t, s.Valid)
+
+	s, err = row.GetString(1)
+	require.NoError(t, err)
+	require.Equal(t, "-42", s.String)
+
+	n, err := row.GetInt64(1)
+	require.NoError(t, err)
+	require.Equal(t, int64(-42), n.Int64)
+	require.True(t, n.Valid)
+
+	b, err := row.GetBool(2)
+	require.NoError(t, err)
+	require.True(t, b.Bool)
+	require.True(t, b.Valid)
+
+	tm, err := row.GetTime(3)
+	require.NoError(t, err)
+	require.True(t, tm.Time.Equal(time.Date(2024, time.February, 29, 10, 30, 0, 0, time.UTC)))
+
+	tm, err = row.GetTime(4)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), tm.Time)
+
+	tm, err = row.GetTime(5)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.February, 29, 12, 30, 0, 5e8, time.UTC), tm.Time)
+
+	data, err := row.GetBytes(6)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 0xff}, data)
+
+	n, err = row.GetInt64(7)
+	require.NoError(t, err)
+	require.False(t, n.Valid)
+
+	data, err = row.GetBytes(7)
+	require.NoError(t, err)
+	require.Nil(t, data)
+}
+
+func TestRowBinary(t *testing.T) {
+	t.Parallel()
+
+	desc := rowDescription(
+		[]string{"name", "small", "big", "oid", "ok", "at", "day"},
+		[]int32{1043, 21, 20, 26, 16, 1184, 1082},
+		[]int16{1, 1, 1, 1, 1, 1, 1},
+	)
+	row := pgwire.NewRow(desc, &pgwire.MsgDataRow{Columns: [][]byte{
+		[]byte("bob"),
+		{0xff, 0xfe},
+		{0, 0, 0, 1, 0, 0, 0, 0},
+		{0xff, 0xff, 0xff, 0xff},
+		{0},
+		{0, 0, 0, 0, 0x3b, 0x9a, 0xca, 0x00},
+		{0xff, 0xff, 0xff, 0xff},
+	}})
+
+	s, err := row.GetString(0)
+	require.NoError(t, err)
+	require.Equal(t, "bob", s.String)
+
+	n, err := row.GetInt64(1)
+	require.NoError(t, err)
+	require.Equal(t, int64(-2), n.Int64)
+
+	n, err = row.GetInt64(2)
+	require.NoError(t, err)
+	require.Equal(t, int64(1)<<32, n.Int64)
+
+	n, err = row.GetInt64(3)
+	require.NoError(t, err)
+	require.Equal(t, int64(1)<<32-1, n.Int64)
+
+	b, err := row.GetBool(4)
+	require.NoError(t, err)
+	require.False(t, b.Bool)
+	require.True(t, b.Valid)
+
+	tm, err := row.GetTime(5)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2000, time.January, 1, 0, 16, 40, 0, time.UTC), tm.Time)
+
+	tm, err = row.GetTime(6)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC), tm.Time)
+
+	_, err = row.GetString(1)
+	require.ErrorIs(t, err, pgwire.ErrUnsupportedType)
+	require.ErrorContains(t, err, `column 1 ("small") of type OID 21`)
+}
+
+func TestRowErrors(t *testing.T) {
+	t.Parallel()
+
+	desc := rowDescription([]string{"id", "n"}, []int32{2950, 23}, []int16{0, 1})
+	row := pgwire.NewRow(desc, &pgwire.MsgDataRow{Columns: [][]byte{
+		[]byte("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"),
+		{0, 1, 2},
+	}})
+
+	_, err := row.GetInt64(0)
+	require.ErrorIs(t, err, pgwire.ErrUnsupportedType)
+	require.ErrorContains(t, err, "type OID 2950")
+
+	_, err = row.GetBool(0)
+	require.ErrorIs(t, err, pgwire.ErrUnsupportedType)
+
+	_, err = row.GetTime(0)
+	require.ErrorIs(t, err, pgwire.ErrUnsupportedType)
+
+	_, err = row.GetInt64(1)
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+
+	_, err = row.GetString(2)
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+
+	_, err = row.GetString(-1)
+	require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+}
+
+func TestRowBinaryTimestampRange(t *testing.T) {
+	t.Parallel()
+
+	epoch := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, want := range []time.Time{
+		time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1600, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1999, time.December, 31, 23, 59, 59, 999999000, time.UTC),
+		time.Date(9999, time.December, 31, 23, 59, 59, 999999000, time.UTC),
+	} {
+		micros := (want.Unix()-epoch.Unix())*1e6 + int64(want.Nanosecond()/1e3)
+
+		desc := rowDescription([]string{"at"}, []int32{1114}, []int16{1})
+		row := pgwire.NewRow(desc, &pgwire.MsgDataRow{Columns: [][]byte{
+			binary.BigEndian.AppendUint64(nil, uint64(micros)),
+		}})
+
+		got, err := row.GetTime(0)
+		require.NoError(t, err)
+		require.Equal(t, want, got.Time, want.String())
+	}
+}
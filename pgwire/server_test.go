@@ -0,0 +1,96 @@
+package pgwire_test
+
+import (
+	"fmt"
+	"gopsql/pgwire"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// echoServer answers every simple query with a single row holding the query
+// text, using only the server side of the package.
+func echoServer(conn net.Conn) error {
+	c := pgwire.NewServerConn(conn)
+	defer c.Close()
+
+	m, err := c.ReceiveStartup()
+	if err != nil {
+		return err
+	}
+
+	startup, ok := m.(*pgwire.MsgStartupMessage)
+	if !ok {
+		return fmt.Errorf("unexpected startup packet %T", m)
+	}
+	user, _ := startup.Get(pgwire.ParamUser)
+
+	err = c.Send(
+		&pgwire.MsgAuthenticationOk{},
+		&pgwire.MsgParameterStatus{Name: "session_authorization", Value: user},
+		&pgwire.MsgBackendKeyData{ProcessID: 1, SecretKey: []byte{0, 0, 0, 1}},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		m, err := c.Receive()
+		if err != nil {
+			return err
+		}
+
+		switch m := m.(type) {
+		case *pgwire.MsgQuery:
+			err = c.Send(
+				pgwire.NewRowDescription([]pgwire.ColumnDescription{
+					{Name: "echo", DataType: 25, Size: -1, Modifier: -1},
+				}),
+				&pgwire.MsgDataRow{Columns: [][]byte{[]byte(m.Value)}},
+				&pgwire.MsgCommandComplete{Tag: "SELECT 1"},
+				&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+			)
+			if err != nil {
+				return err
+			}
+		case *pgwire.MsgTerminate:
+			return nil
+		default:
+			return fmt.Errorf("unexpected message %T", m)
+		}
+	}
+}
+
+func TestEchoServer(t *testing.T) {
+	t.Parallel()
+
+	client, server := net.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- echoServer(server)
+	}()
+
+	c := pgwire.NewConn(client)
+
+	params, _, _, err := c.Handshake(&pgwire.MsgStartupMessage{
+		ProtocolVersion: pgwire.ProtocolVersion(pgwire.ProtocolVersion3_0),
+		Parameters: []pgwire.StartupParameter{
+			{Name: pgwire.ParamUser, Value: "alice"},
+		},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "alice", params["session_authorization"])
+
+	for _, sql := range []string{"SELECT 1", "hello"} {
+		result, err := c.SimpleQuery(sql)
+		require.NoError(t, err)
+		require.Equal(t, [][][]byte{{[]byte(sql)}}, result.Rows)
+	}
+
+	err = c.Close()
+	require.NoError(t, err)
+	require.NoError(t, <-done)
+}
@@ -1,8 +1,10 @@
 package pgwire
 
 import (
+	"encoding/binary"
 	"fmt"
 	"gopsql/pgio"
+	"strings"
 )
 
 var (
@@ -21,6 +23,15 @@ func unexpectedAuthKind(got int32, want AuthenticationKind) error {
 	return fmt.Errorf("%w: got '%d', want '%d'", ErrUnexpectedKind, got, want)
 }
 
+// decodeError adds the kind of the frame being decoded, and the type chosen
+// for it when known, to err.
+func decodeError(kind byte, m Message, err error) error {
+	if m == nil {
+		return fmt.Errorf("message kind '%c': %w", kind, err)
+	}
+	return fmt.Errorf("decode %T (kind '%c'): %w", m, kind, err)
+}
+
 func shiftLength(in []byte) ([]byte, error) {
 	length, b, err := pgio.ShiftInt32(in)
 	if err != nil {
@@ -29,7 +40,7 @@ func shiftLength(in []byte) ([]byte, error) {
 
 	size := int(length) - 4
 
-	if size > len(b) {
+	if size < 0 || size > len(b) {
 		return in, pgio.ErrValueUnderflow
 	}
 	return b[:size], nil
@@ -47,3 +58,97 @@ func shiftHeader(msg MessageKind, in []byte) ([]byte, error) {
 
 	return shiftLength(b)
 }
+
+// shiftNullableBytes shifts an int32 length followed by that many bytes,
+// copied. A length of -1 is NULL and returns nil; any other negative length
+// is invalid.
+func shiftNullableBytes(buf *pgio.Buffer) ([]byte, error) {
+	return shiftNullableBytesInto(buf, nil)
+}
+
+// shiftNullableBytesInto is like shiftNullableBytes, but copies into dst
+// when its capacity allows.
+func shiftNullableBytesInto(buf *pgio.Buffer, dst []byte) ([]byte, error) {
+	length, err := buf.ShiftInt32()
+	if err != nil {
+		return nil, err
+	}
+
+	if length == -1 {
+		return nil, nil
+	}
+
+	if length < 0 {
+		return nil, pgio.ErrInvalidValue
+	}
+	return buf.ShiftBytesInto(dst, int(length))
+}
+
+func lookupField(fields []byte, values []string, kind FieldKind) (string, bool) {
+	for i, field := range fields {
+		if FieldKind(field) == kind && i < len(values) {
+			return values[i], true
+		}
+	}
+	return "", false
+}
+
+// setField replaces the first value of kind, or appends it when absent.
+func setField(fields []byte, values []string, kind FieldKind, value string) ([]byte, []string) {
+	for i, field := range fields {
+		if FieldKind(field) == kind && i < len(values) {
+			values[i] = value
+			return fields, values
+		}
+	}
+	return append(fields, byte(kind)), append(values, value)
+}
+
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	cloned := make([]string, len(values))
+
+	for i, value := range values {
+		cloned[i] = strings.Clone(value)
+	}
+	return cloned
+}
+
+// checkCount rejects a decoded element count that is negative or that the
+// rest of the body cannot hold when each element takes at least size bytes.
+// The comparison divides rather than multiplies so that a large count cannot
+// overflow past it.
+func checkCount(buf *pgio.Buffer, count, size int) error {
+	if count < 0 || buf.Len()/size < count {
+		return pgio.ErrValueUnderflow
+	}
+	return nil
+}
+
+func secretKeyUint32(key []byte) (uint32, bool) {
+	if len(key) != 4 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(key), true
+}
+
+// validateSecretKey checks the length of a cancellation key: protocol 3.0
+// defines it as an Int32, and 3.2 allows up to 256 bytes.
+func validateSecretKey(key []byte, version ProtocolVersion) error {
+	if version < ProtocolVersion(ProtocolVersion3_2) && len(key) != 4 {
+		return fmt.Errorf("%w: %d byte secret key under protocol %d.%d",
+			ErrInvalidFormat, len(key), version.Major(), version.Minor())
+	}
+
+	if len(key) < 4 {
+		return invalidFormat(pgio.ErrValueUnderflow)
+	}
+
+	if len(key) > 256 {
+		return invalidFormat(pgio.ErrValueOverflow)
+	}
+	return nil
+}
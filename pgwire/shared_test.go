@@ -1,6 +1,8 @@
 package pgwire_test
 
 import (
+	"bytes"
+	"gopsql/pgio"
 	"gopsql/pgwire"
 	"testing"
 
@@ -21,5 +23,133 @@ func testMessage(t *testing.T, b []byte, m pgwire.Message, fn func(*testing.T))
 		got, err := m.AppendBinary(nil)
 		require.NoError(t, err)
 		require.Equal(t, b, got)
+
+		if m.Kind() != pgwire.MessageKindNone {
+			require.Equal(t, byte(m.Kind()), got[0])
+		}
 	})
 }
+
+// script is a connection that replays canned messages to the reader and
+// records everything written to it.
+type script struct {
+	in  *bytes.Reader
+	out bytes.Buffer
+}
+
+func newScript(t *testing.T, msgs ...pgwire.Message) *script {
+	return &script{in: bytes.NewReader(appendMessages(t, msgs...))}
+}
+
+func (x *script) Read(p []byte) (int, error) {
+	return x.in.Read(p)
+}
+
+func (x *script) Write(p []byte) (int, error) {
+	return x.out.Write(p)
+}
+
+func appendMessages(t *testing.T, msgs ...pgwire.Message) []byte {
+	var b []byte
+
+	for _, m := range msgs {
+		var err error
+
+		b, err = m.AppendBinary(b)
+		require.NoError(t, err)
+	}
+	return b
+}
+
+func TestNullableLength(t *testing.T) {
+	t.Parallel()
+
+	frame := func(kind pgwire.MessageKind, fn func(*pgio.Buffer)) []byte {
+		body := pgio.NewBuffer(nil)
+		fn(body)
+
+		buf := pgio.NewBuffer(nil)
+		buf.AppendByte(byte(kind))
+		buf.AppendInt32(int32(4 + len(body.Bytes())))
+		buf.AppendByte(body.Bytes()...)
+		return buf.Bytes()
+	}
+
+	// Each decoder gets a frame holding one length-prefixed value and
+	// returns the decoded value.
+	decoders := map[string]func(length int32) ([]byte, error){
+		"DataRow": func(length int32) ([]byte, error) {
+			var m pgwire.MsgDataRow
+			err := m.UnmarshalBinary(frame(pgwire.MessageKindDataRow, func(buf *pgio.Buffer) {
+				buf.AppendInt16(1)
+				buf.AppendInt32(length)
+			}))
+			if err != nil {
+				return nil, err
+			}
+			return m.Columns[0], nil
+		},
+		"FunctionCallResponse": func(length int32) ([]byte, error) {
+			var m pgwire.MsgFunctionCallResponse
+			err := m.UnmarshalBinary(frame(pgwire.MessageKindFunctionCallResponse, func(buf *pgio.Buffer) {
+				buf.AppendInt32(length)
+			}))
+			return m.Result, err
+		},
+		"Bind": func(length int32) ([]byte, error) {
+			var m pgwire.MsgBind
+			err := m.UnmarshalBinary(frame(pgwire.MessageKindBind, func(buf *pgio.Buffer) {
+				buf.AppendString("", "")
+				buf.AppendInt16(0)
+				buf.AppendInt16(1)
+				buf.AppendInt32(length)
+				buf.AppendInt16(0)
+			}))
+			if err != nil {
+				return nil, err
+			}
+			return m.ParameterData[0], nil
+		},
+		"FunctionCall": func(length int32) ([]byte, error) {
+			var m pgwire.MsgFunctionCall
+			err := m.UnmarshalBinary(frame(pgwire.MessageKindFunctionCall, func(buf *pgio.Buffer) {
+				buf.AppendInt32(1)
+				buf.AppendInt16(0)
+				buf.AppendInt16(1)
+				buf.AppendInt32(length)
+				buf.AppendInt16(0)
+			}))
+			if err != nil {
+				return nil, err
+			}
+			return m.ArgumentValues[0], nil
+		},
+		"SASLInitialResponse": func(length int32) ([]byte, error) {
+			var m pgwire.MsgSASLInitialResponse
+			err := m.UnmarshalBinary(frame(pgwire.MessageKindSASLInitialResponse, func(buf *pgio.Buffer) {
+				buf.AppendString("SCRAM-SHA-256")
+				buf.AppendInt32(length)
+			}))
+			return m.Response, err
+		},
+	}
+
+	for name, decode := range decoders {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			value, err := decode(-1)
+			require.NoError(t, err)
+			require.Nil(t, value)
+
+			value, err = decode(0)
+			require.NoError(t, err)
+			require.NotNil(t, value)
+			require.Empty(t, value)
+
+			_, err = decode(-2)
+			require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+			require.ErrorIs(t, err, pgio.ErrInvalidValue)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package pgwire
+
+import "fmt"
+
+// CompleteStartup reads the messages the server sends after
+// AuthenticationOk, collecting the reported run-time parameters and the
+// cancellation key until ReadyForQuery arrives. An ErrorResponse is
+// returned as a *PgError. A strict reader also rejects any ParameterStatus
+// that fails Validate.
+func CompleteStartup(r *MessageReader) (params map[string]string, key *MsgBackendKeyData, txStatus TransactionStatusKind, err error) {
+	params = make(map[string]string)
+
+	for {
+		var m Backend
+
+		m, err = r.ReadBackend()
+		if err != nil {
+			return nil, nil, 0, err
+		}
+
+		switch m := m.(type) {
+		case *MsgParameterStatus:
+			if r.strict {
+				err = m.Validate()
+				if err != nil {
+					return nil, nil, 0, err
+				}
+			}
+			params[m.Name] = m.Value
+		case *MsgBackendKeyData:
+			key = m
+		case *MsgErrorResponse:
+			return nil, nil, 0, newPgError(m)
+		case *MsgReadyForQuery:
+			return params, key, TransactionStatusKind(m.TxStatus), nil
+		}
+	}
+}
+
+// AuthState is the progress of the authentication exchange on a Conn.
+type AuthState int
+
+const (
+	AuthStateNone AuthState = iota
+	AuthStateSASLInProgress
+	AuthStateGSSInProgress
+	AuthStateComplete
+)
+
+// Next returns the state after the authentication request req. It fails
+// with ErrUnexpectedKind when req cannot follow x, such as a
+// SASLContinue without a SASL exchange in progress or any request after
+// AuthenticationOk.
+func (x AuthState) Next(req Backend) (AuthState, error) {
+	if x == AuthStateComplete {
+		return x, fmt.Errorf("%w: %T after AuthenticationOk", ErrUnexpectedKind, req)
+	}
+
+	switch req.(type) {
+	case *MsgAuthenticationOk:
+		return AuthStateComplete, nil
+	case *MsgAuthenticationSASL:
+		if x == AuthStateNone {
+			return AuthStateSASLInProgress, nil
+		}
+	case *MsgAuthenticationSASLContinue, *MsgAuthenticationSASLFinal:
+		if x == AuthStateSASLInProgress {
+			return x, nil
+		}
+	case *MsgAuthenticationGSS, *MsgAuthenticationSSPI:
+		if x == AuthStateNone {
+			return AuthStateGSSInProgress, nil
+		}
+	case *MsgAuthenticationGSSContinue:
+		if x == AuthStateGSSInProgress {
+			return x, nil
+		}
+	case *MsgAuthenticationKerberosV5,
+		*MsgAuthenticationCleartextPassword,
+		*MsgAuthenticationMD5Password:
+		if x == AuthStateNone {
+			return x, nil
+		}
+	default:
+		return x, fmt.Errorf("%w: %T is not an authentication request", ErrUnexpectedKind, req)
+	}
+	return x, fmt.Errorf("%w: %T during %s", ErrUnexpectedKind, req, x)
+}
+
+func (x AuthState) String() string {
+	switch x {
+	case AuthStateNone:
+		return "None"
+	case AuthStateSASLInProgress:
+		return "SASLInProgress"
+	case AuthStateGSSInProgress:
+		return "GSSInProgress"
+	case AuthStateComplete:
+		return "Complete"
+	}
+	return fmt.Sprintf("AuthState(%d)", int(x))
+}
+
+// AuthFunc answers an authentication request from the server. It returns the
+// message to send back, or nil when the request needs no reply, as with
+// AuthenticationSASLFinal.
+type AuthFunc func(req Backend) (Frontend, error)
+
+// Handshake sends startup and answers the server's authentication requests
+// with auth until AuthenticationOk, then completes startup as
+// CompleteStartup does. auth may be nil for servers that do not ask for
+// credentials, such as those using trust authentication.
+func (c *Conn) Handshake(startup *MsgStartupMessage, auth AuthFunc) (params map[string]string, key *MsgBackendKeyData, txStatus TransactionStatusKind, err error) {
+	err = c.Send(startup)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	c.protocolVersion = startup.ProtocolVersion
+
+	err = c.authenticate(auth)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	params, key, txStatus, err = CompleteStartup(c.reader)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	if key != nil {
+		err = key.Validate(c.protocolVersion)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+	}
+
+	c.txStatus = txStatus
+	return params, key, txStatus, nil
+}
+
+func (c *Conn) authenticate(auth AuthFunc) error {
+	for {
+		m, err := c.receiveBackend()
+		if err != nil {
+			return err
+		}
+
+		switch m := m.(type) {
+		case *MsgAuthenticationOk:
+			c.authState = AuthStateComplete
+			return nil
+		case *MsgAuthenticationKerberosV5,
+			*MsgAuthenticationCleartextPassword,
+			*MsgAuthenticationMD5Password,
+			*MsgAuthenticationGSS,
+			*MsgAuthenticationGSSContinue,
+			*MsgAuthenticationSSPI,
+			*MsgAuthenticationSASL,
+			*MsgAuthenticationSASLContinue,
+			*MsgAuthenticationSASLFinal:
+			if auth == nil {
+				return fmt.Errorf("%w: server requested authentication with %T", ErrUnexpectedKind, m)
+			}
+
+			c.authState, err = c.authState.Next(m)
+			if err != nil {
+				return err
+			}
+
+			resp, err := auth(m)
+			if err != nil {
+				return err
+			}
+
+			if resp != nil {
+				err = c.Send(resp)
+				if err != nil {
+					return err
+				}
+			}
+		case *MsgNegotiateProtocolVersion:
+			// The server continues with the version and options it
+			// supports.
+			c.protocolVersion = ProtocolVersion(major3<<16 | m.MinorVersionSupported)
+		case *MsgErrorResponse:
+			return newPgError(m)
+		default:
+			return fmt.Errorf("%w: %T during authentication", ErrUnexpectedKind, m)
+		}
+	}
+}
+
+// AuthState returns the progress of the authentication exchange: for a
+// client, as of the last request received; for a server, as of the last
+// request sent.
+func (c *Conn) AuthState() AuthState {
+	return c.authState
+}
+
+// ProtocolVersion returns the protocol version in effect after Handshake:
+// the one requested, or the one the server fell back to in a
+// NegotiateProtocolVersion.
+func (c *Conn) ProtocolVersion() ProtocolVersion {
+	return c.protocolVersion
+}
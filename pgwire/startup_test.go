@@ -0,0 +1,380 @@
+package pgwire_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopsql/pgwire"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteStartup(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgParameterStatus{Name: "server_version", Value: "17.2"},
+		&pgwire.MsgParameterStatus{Name: "client_encoding", Value: "UTF8"},
+		&pgwire.MsgNoticeResponse{
+			Fields: []byte{byte(pgwire.FieldKindSeverity)},
+			Values: []string{"NOTICE"},
+		},
+		&pgwire.MsgBackendKeyData{ProcessID: 4321, SecretKey: []byte{1, 2, 3, 4}},
+		&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+	)
+
+	params, key, txStatus, err := pgwire.CompleteStartup(pgwire.NewMessageReader(s))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"server_version":  "17.2",
+		"client_encoding": "UTF8",
+	}, params)
+	require.Equal(t, &pgwire.MsgBackendKeyData{ProcessID: 4321, SecretKey: []byte{1, 2, 3, 4}}, key)
+	require.Equal(t, pgwire.TransactionStatusKindIdle, txStatus)
+}
+
+func TestCompleteStartupValidatesParameters(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		m    *pgwire.MsgParameterStatus
+		want string
+	}{
+		{"EmptyName", &pgwire.MsgParameterStatus{Name: "", Value: "UTF8"}, "empty parameter name"},
+		{"InvalidValue", &pgwire.MsgParameterStatus{Name: "application_name", Value: "\xff"}, "not valid UTF-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs := []pgwire.Message{
+				tt.m,
+				&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+			}
+
+			// Lenient readers accumulate the entry as sent.
+			_, _, _, err := pgwire.CompleteStartup(pgwire.NewMessageReader(newScript(t, msgs...)))
+			require.NoError(t, err)
+
+			r := pgwire.NewMessageReader(newScript(t, msgs...))
+			r.SetStrict(true)
+
+			_, _, _, err = pgwire.CompleteStartup(r)
+			require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestCompleteStartupError(t *testing.T) {
+	t.Parallel()
+
+	s := newScript(t,
+		&pgwire.MsgErrorResponse{
+			Fields: []byte{
+				byte(pgwire.FieldKindSeverity),
+				byte(pgwire.FieldKindCode),
+				byte(pgwire.FieldKindMessage),
+			},
+			Values: []string{"FATAL", "53300", "too many connections"},
+		},
+	)
+
+	_, _, _, err := pgwire.CompleteStartup(pgwire.NewMessageReader(s))
+
+	var pgErr *pgwire.PgError
+	require.True(t, errors.As(err, &pgErr))
+	require.Equal(t, "FATAL: too many connections (SQLSTATE 53300)", pgErr.Error())
+}
+
+func TestConnHandshake(t *testing.T) {
+	t.Parallel()
+
+	startup := &pgwire.MsgStartupMessage{
+		ProtocolVersion: pgwire.ProtocolVersion(pgwire.ProtocolVersion3_0),
+		Parameters: []pgwire.StartupParameter{
+			{Name: pgwire.ParamUser, Value: "postgres"},
+		},
+	}
+
+	t.Run("Trust", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			&pgwire.MsgAuthenticationOk{},
+			&pgwire.MsgParameterStatus{Name: "server_version", Value: "17.2"},
+			&pgwire.MsgBackendKeyData{ProcessID: 4321, SecretKey: []byte{1, 2, 3, 4}},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		auth := func(req pgwire.Backend) (pgwire.Frontend, error) {
+			t.Errorf("unexpected authentication request %T", req)
+			return nil, nil
+		}
+
+		params, key, txStatus, err := pgwire.NewConn(s).Handshake(startup, auth)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"server_version": "17.2"}, params)
+		require.Equal(t, int32(4321), key.ProcessID)
+		require.Equal(t, pgwire.TransactionStatusKindIdle, txStatus)
+		require.Equal(t, appendMessages(t, startup), s.out.Bytes())
+	})
+
+	t.Run("TrustWithoutAuth", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			&pgwire.MsgAuthenticationOk{},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		_, _, _, err := pgwire.NewConn(s).Handshake(startup, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("MD5", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			pgwire.NewAuthenticationMD5PasswordSalt([4]byte{1, 2, 3, 4}),
+			&pgwire.MsgAuthenticationOk{},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		auth := func(req pgwire.Backend) (pgwire.Frontend, error) {
+			md5, ok := req.(*pgwire.MsgAuthenticationMD5Password)
+			require.True(t, ok)
+			return pgwire.MD5Password("postgres", "secret", md5.Salt), nil
+		}
+
+		_, _, _, err := pgwire.NewConn(s).Handshake(startup, auth)
+		require.NoError(t, err)
+		require.Equal(t, appendMessages(t,
+			startup,
+			pgwire.MD5Password("postgres", "secret", [4]byte{1, 2, 3, 4}),
+		), s.out.Bytes())
+	})
+
+	t.Run("GSS", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			&pgwire.MsgAuthenticationGSS{},
+			&pgwire.MsgAuthenticationGSSContinue{Data: []byte("server-1")},
+			&pgwire.MsgAuthenticationGSSContinue{Data: []byte("server-2")},
+			&pgwire.MsgAuthenticationOk{},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		round := 0
+		auth := func(req pgwire.Backend) (pgwire.Frontend, error) {
+			round++
+			return &pgwire.MsgGSSResponse{Data: fmt.Appendf(nil, "client-%d", round)}, nil
+		}
+
+		_, _, _, err := pgwire.NewConn(s).Handshake(startup, auth)
+		require.NoError(t, err)
+		require.Equal(t, 3, round)
+
+		// Read the exchange back as the server would, pairing each reply
+		// with the request it answers.
+		server := pgwire.NewServerConn(&script{in: bytes.NewReader(s.out.Bytes())})
+
+		_, err = server.ReceiveStartup()
+		require.NoError(t, err)
+
+		requests := []pgwire.Backend{
+			&pgwire.MsgAuthenticationGSS{},
+			&pgwire.MsgAuthenticationGSSContinue{},
+			&pgwire.MsgAuthenticationGSSContinue{},
+		}
+
+		for i, req := range requests {
+			m, err := server.ReceiveAuthResponse(req)
+			require.NoError(t, err)
+			require.Equal(t, &pgwire.MsgGSSResponse{Data: fmt.Appendf(nil, "client-%d", i+1)}, m)
+		}
+	})
+
+	t.Run("SASL", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			&pgwire.MsgAuthenticationSASL{Mechanisms: []string{"SCRAM-SHA-256"}},
+			&pgwire.MsgAuthenticationSASLContinue{Data: []byte("server-first")},
+			&pgwire.MsgAuthenticationSASLFinal{Data: []byte("server-final")},
+			&pgwire.MsgAuthenticationOk{},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		c := pgwire.NewConn(s)
+		require.Equal(t, pgwire.AuthStateNone, c.AuthState())
+
+		var states []pgwire.AuthState
+
+		auth := func(req pgwire.Backend) (pgwire.Frontend, error) {
+			states = append(states, c.AuthState())
+
+			switch req.(type) {
+			case *pgwire.MsgAuthenticationSASL:
+				return &pgwire.MsgSASLInitialResponse{Name: "SCRAM-SHA-256", Response: []byte("client-first")}, nil
+			case *pgwire.MsgAuthenticationSASLContinue:
+				return &pgwire.MsgSASLResponse{Data: []byte("client-final")}, nil
+			}
+			return nil, nil
+		}
+
+		_, _, _, err := c.Handshake(startup, auth)
+		require.NoError(t, err)
+		require.Equal(t, []pgwire.AuthState{
+			pgwire.AuthStateSASLInProgress,
+			pgwire.AuthStateSASLInProgress,
+			pgwire.AuthStateSASLInProgress,
+		}, states)
+		require.Equal(t, pgwire.AuthStateComplete, c.AuthState())
+
+		// The server side picks the decoder for each 'p' reply from the
+		// request it last sent.
+		server := pgwire.NewServerConn(&script{in: bytes.NewReader(s.out.Bytes())})
+
+		_, err = server.ReceiveStartup()
+		require.NoError(t, err)
+
+		err = server.Send(&pgwire.MsgAuthenticationSASL{Mechanisms: []string{"SCRAM-SHA-256"}})
+		require.NoError(t, err)
+		require.Equal(t, pgwire.AuthStateSASLInProgress, server.AuthState())
+
+		m, err := server.ReceiveAuthResponse(nil)
+		require.NoError(t, err)
+		require.Equal(t, &pgwire.MsgSASLInitialResponse{Name: "SCRAM-SHA-256", Response: []byte("client-first")}, m)
+
+		err = server.Send(&pgwire.MsgAuthenticationSASLContinue{Data: []byte("server-first")})
+		require.NoError(t, err)
+
+		m, err = server.ReceiveAuthResponse(nil)
+		require.NoError(t, err)
+		require.Equal(t, &pgwire.MsgSASLResponse{Data: []byte("client-final")}, m)
+
+		err = server.Send(
+			&pgwire.MsgAuthenticationSASLFinal{Data: []byte("server-final")},
+			&pgwire.MsgAuthenticationOk{},
+		)
+		require.NoError(t, err)
+		require.Equal(t, pgwire.AuthStateComplete, server.AuthState())
+	})
+
+	t.Run("SASLContinueFirst", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t, &pgwire.MsgAuthenticationSASLContinue{Data: []byte("server-first")})
+
+		auth := func(req pgwire.Backend) (pgwire.Frontend, error) {
+			t.Errorf("unexpected authentication request %T", req)
+			return nil, nil
+		}
+
+		_, _, _, err := pgwire.NewConn(s).Handshake(startup, auth)
+		require.ErrorIs(t, err, pgwire.ErrUnexpectedKind)
+	})
+
+	t.Run("PasswordWithoutAuth", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t, &pgwire.MsgAuthenticationCleartextPassword{})
+
+		_, _, _, err := pgwire.NewConn(s).Handshake(startup, nil)
+		require.ErrorIs(t, err, pgwire.ErrUnexpectedKind)
+	})
+
+	t.Run("LongKey", func(t *testing.T) {
+		t.Parallel()
+
+		key := &pgwire.MsgBackendKeyData{ProcessID: 4321, SecretKey: make([]byte, 32)}
+
+		s := newScript(t,
+			&pgwire.MsgAuthenticationOk{},
+			key,
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		_, _, _, err := pgwire.NewConn(s).Handshake(startup, nil)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+
+		s = newScript(t,
+			&pgwire.MsgAuthenticationOk{},
+			key,
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		startup3_2 := &pgwire.MsgStartupMessage{
+			ProtocolVersion: pgwire.ProtocolVersion(pgwire.ProtocolVersion3_2),
+			Parameters:      startup.Parameters,
+		}
+
+		c := pgwire.NewConn(s)
+		_, got, _, err := c.Handshake(startup3_2, nil)
+		require.NoError(t, err)
+		require.Equal(t, key, got)
+		require.Equal(t, pgwire.ProtocolVersion(pgwire.ProtocolVersion3_2), c.ProtocolVersion())
+	})
+
+	t.Run("LongKeyAfterNegotiation", func(t *testing.T) {
+		t.Parallel()
+
+		s := newScript(t,
+			&pgwire.MsgNegotiateProtocolVersion{MinorVersionSupported: 0},
+			&pgwire.MsgAuthenticationOk{},
+			&pgwire.MsgBackendKeyData{ProcessID: 4321, SecretKey: make([]byte, 32)},
+			&pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+		)
+
+		startup3_2 := &pgwire.MsgStartupMessage{
+			ProtocolVersion: pgwire.ProtocolVersion(pgwire.ProtocolVersion3_2),
+			Parameters:      startup.Parameters,
+		}
+
+		c := pgwire.NewConn(s)
+		_, _, _, err := c.Handshake(startup3_2, nil)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat)
+		require.Equal(t, pgwire.ProtocolVersion(pgwire.ProtocolVersion3_0), c.ProtocolVersion())
+	})
+}
+
+func TestAuthStateNext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		state pgwire.AuthState
+		req   pgwire.Backend
+		want  pgwire.AuthState
+		err   bool
+	}{
+		{pgwire.AuthStateNone, &pgwire.MsgAuthenticationSASL{}, pgwire.AuthStateSASLInProgress, false},
+		{pgwire.AuthStateSASLInProgress, &pgwire.MsgAuthenticationSASLContinue{}, pgwire.AuthStateSASLInProgress, false},
+		{pgwire.AuthStateSASLInProgress, &pgwire.MsgAuthenticationSASLFinal{}, pgwire.AuthStateSASLInProgress, false},
+		{pgwire.AuthStateSASLInProgress, &pgwire.MsgAuthenticationOk{}, pgwire.AuthStateComplete, false},
+		{pgwire.AuthStateNone, &pgwire.MsgAuthenticationGSS{}, pgwire.AuthStateGSSInProgress, false},
+		{pgwire.AuthStateGSSInProgress, &pgwire.MsgAuthenticationGSSContinue{}, pgwire.AuthStateGSSInProgress, false},
+		{pgwire.AuthStateNone, &pgwire.MsgAuthenticationMD5Password{}, pgwire.AuthStateNone, false},
+		{pgwire.AuthStateNone, &pgwire.MsgAuthenticationOk{}, pgwire.AuthStateComplete, false},
+		{pgwire.AuthStateNone, &pgwire.MsgAuthenticationSASLContinue{}, 0, true},
+		{pgwire.AuthStateSASLInProgress, &pgwire.MsgAuthenticationGSSContinue{}, 0, true},
+		{pgwire.AuthStateGSSInProgress, &pgwire.MsgAuthenticationSASL{}, 0, true},
+		{pgwire.AuthStateComplete, &pgwire.MsgAuthenticationOk{}, 0, true},
+		{pgwire.AuthStateNone, &pgwire.MsgReadyForQuery{}, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.state.Next(tt.req)
+		if tt.err {
+			require.ErrorIs(t, err, pgwire.ErrUnexpectedKind, "%s %T", tt.state, tt.req)
+			continue
+		}
+
+		require.NoError(t, err, "%s %T", tt.state, tt.req)
+		require.Equal(t, tt.want, got, "%s %T", tt.state, tt.req)
+	}
+}
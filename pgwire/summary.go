@@ -0,0 +1,55 @@
+package pgwire
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Summary returns a compact one line description of m for logging, such as
+// "DataRow(4 cols)" or "ReadyForQuery(idle)".
+func Summary(m Message) string {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", m), "*pgwire.Msg")
+
+	switch m := m.(type) {
+	case *MsgCommandComplete:
+		return name + "(" + m.Tag + ")"
+	case *MsgDataRow:
+		return fmt.Sprintf("%s(%d cols)", name, len(m.Columns))
+	case *MsgErrorResponse:
+		return name + "(" + summarizeFields(m.Fields, m.Values) + ")"
+	case *MsgNoticeResponse:
+		return name + "(" + summarizeFields(m.Fields, m.Values) + ")"
+	case *MsgNotificationResponse:
+		return fmt.Sprintf("%s(%s: %s)", name, m.Channel, m.Payload)
+	case *MsgParameterStatus:
+		return name + "(" + m.Name + "=" + m.Value + ")"
+	case *MsgQuery:
+		return name + "(" + m.Value + ")"
+	case *MsgReadyForQuery:
+		return name + "(" + summarizeTxStatus(m.TxStatus) + ")"
+	case *MsgRowDescription:
+		return name + "(" + strings.Join(m.Names, ", ") + ")"
+	case *MsgParse:
+		return fmt.Sprintf("%s(%q: %s)", name, m.DestinationStatementName, m.Query)
+	}
+	return name
+}
+
+func summarizeFields(fields []byte, values []string) string {
+	severity, _ := lookupField(fields, values, FieldKindSeverity)
+	code, _ := lookupField(fields, values, FieldKindCode)
+	message, _ := lookupField(fields, values, FieldKindMessage)
+	return severity + " " + code + ": " + message
+}
+
+func summarizeTxStatus(status byte) string {
+	switch TransactionStatusKind(status) {
+	case TransactionStatusKindIdle:
+		return "idle"
+	case TransactionStatusKindActive:
+		return "in transaction"
+	case TransactionStatusKindError:
+		return "failed"
+	}
+	return fmt.Sprintf("%q", status)
+}
@@ -0,0 +1,63 @@
+package pgwire_test
+
+import (
+	"gopsql/pgwire"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		m    pgwire.Message
+		want string
+	}{
+		{
+			m:    &pgwire.MsgDataRow{Columns: make([][]byte, 4)},
+			want: "DataRow(4 cols)",
+		},
+		{
+			m: &pgwire.MsgErrorResponse{
+				Fields: []byte{
+					byte(pgwire.FieldKindSeverity),
+					byte(pgwire.FieldKindCode),
+					byte(pgwire.FieldKindMessage),
+				},
+				Values: []string{"ERROR", "23505", "duplicate key"},
+			},
+			want: "ErrorResponse(ERROR 23505: duplicate key)",
+		},
+		{
+			m:    &pgwire.MsgCommandComplete{Tag: "INSERT 0 1"},
+			want: "CommandComplete(INSERT 0 1)",
+		},
+		{
+			m:    &pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindIdle)},
+			want: "ReadyForQuery(idle)",
+		},
+		{
+			m:    &pgwire.MsgReadyForQuery{TxStatus: byte(pgwire.TransactionStatusKindError)},
+			want: "ReadyForQuery(failed)",
+		},
+		{
+			m:    &pgwire.MsgParameterStatus{Name: "TimeZone", Value: "UTC"},
+			want: "ParameterStatus(TimeZone=UTC)",
+		},
+		{
+			m:    &pgwire.MsgQuery{Value: "SELECT 1"},
+			want: "Query(SELECT 1)",
+		},
+		{
+			m:    &pgwire.MsgSync{},
+			want: "Sync",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			require.Equal(t, tt.want, pgwire.Summary(tt.m))
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package pgwire
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseBoolText parses a boolean in the text format the server sends,
+// "t" or "f".
+func ParseBoolText(s string) (bool, error) {
+	switch s {
+	case "t":
+		return true, nil
+	case "f":
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: invalid boolean %q", ErrInvalidFormat, s)
+}
+
+// ParseDateText parses a date in the ISO text format, such as "2006-01-02"
+// or "0044-03-15 BC". The result is midnight UTC.
+func ParseDateText(s string) (time.Time, error) {
+	t, err := parseEra(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, invalidFormat(err)
+	}
+	return t, nil
+}
+
+// ParseTimestamptzText parses a timestamp with time zone in the ISO text
+// format, such as "2006-01-02 15:04:05.999999+07". The fractional seconds are
+// optional and the offset may include minutes and seconds.
+func ParseTimestamptzText(s string) (time.Time, error) {
+	layouts := [...]string{
+		"2006-01-02 15:04:05Z07",
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02 15:04:05Z07:00:00",
+	}
+
+	var err error
+
+	for _, layout := range layouts {
+		var t time.Time
+
+		t, err = parseEra(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, invalidFormat(err)
+}
+
+// ParseArrayText parses a one-dimensional array in the text format, such as
+// `{1,2,3}` or `{"a","b,c",NULL}`, into its elements. A NULL element is
+// returned as an empty string; ParseArrayTextNulls tells the two apart.
+// Quoted elements may escape any character with a backslash.
+func ParseArrayText(s string) ([]string, error) {
+	elems, _, err := ParseArrayTextNulls(s)
+	return elems, err
+}
+
+// ParseArrayTextNulls parses an array as ParseArrayText does and also
+// reports, for each element, whether it is NULL.
+func ParseArrayTextNulls(s string) (elems []string, nulls []bool, err error) {
+	inner, ok := strings.CutPrefix(s, "{")
+	if ok {
+		inner, ok = strings.CutSuffix(inner, "}")
+	}
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: invalid array %q", ErrInvalidFormat, s)
+	}
+
+	elems = []string{}
+	nulls = []bool{}
+
+	if strings.TrimSpace(inner) == "" {
+		return elems, nulls, nil
+	}
+
+	for {
+		elem, rest, err := shiftArrayElem(inner)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid array %q: %w", ErrInvalidFormat, s, err)
+		}
+		elems = append(elems, string(elem))
+		nulls = append(nulls, elem == nil)
+
+		if rest == "" {
+			return elems, nulls, nil
+		}
+		inner = rest[1:] // the comma
+	}
+}
+
+// shiftArrayElem reads one element from s and returns the rest of s, which
+// is empty or starts with the comma before the next element.
+func shiftArrayElem(s string) ([]byte, string, error) {
+	s = strings.TrimLeft(s, " \t\n")
+
+	elem := []byte{}
+	quoted := strings.HasPrefix(s, `"`)
+	escaped := false
+
+	// keep is the length of elem up to its last escaped character, which
+	// survives the trimming of trailing whitespace.
+	keep := 0
+
+	if quoted {
+		s = s[1:]
+	}
+
+	for {
+		if s == "" {
+			if quoted {
+				return nil, "", errors.New("unterminated quote")
+			}
+			break
+		}
+
+		c := s[0]
+
+		if c == '\\' {
+			if len(s) < 2 {
+				return nil, "", errors.New("trailing backslash")
+			}
+			elem = append(elem, s[1])
+			escaped = true
+			keep = len(elem)
+			s = s[2:]
+			continue
+		}
+
+		if quoted && c == '"' {
+			s = strings.TrimLeft(s[1:], " \t\n")
+			if s != "" && s[0] != ',' {
+				return nil, "", errors.New("text after quoted element")
+			}
+			return elem, s, nil
+		}
+
+		if !quoted {
+			if c == ',' {
+				break
+			}
+			if c == '"' || c == '{' || c == '}' {
+				return nil, "", fmt.Errorf("unexpected %q", c)
+			}
+		}
+
+		elem = append(elem, c)
+		s = s[1:]
+	}
+
+	elem = elem[:keep+len(bytes.TrimRight(elem[keep:], " \t\n"))]
+
+	if len(elem) == 0 {
+		return nil, "", errors.New("empty element")
+	}
+
+	if !escaped && strings.EqualFold(string(elem), "NULL") {
+		return nil, s, nil
+	}
+	return elem, s, nil
+}
+
+// parseEra parses s with layout, which must start with a four digit year,
+// allowing the BC suffix the server writes for years before 1 AD. Such years
+// are converted to Go's astronomical numbering, where 1 BC is year 0. The
+// date is validated against that year, so February 29 of a BC leap year
+// such as 1 BC is accepted.
+func parseEra(layout, s string) (time.Time, error) {
+	s, bc := strings.CutSuffix(s, " BC")
+	if !bc || len(s) < 4 {
+		return time.Parse(layout, s)
+	}
+
+	year, err := strconv.Atoi(s[:4])
+	if err != nil || year < 1 {
+		return time.Time{}, fmt.Errorf("invalid BC year %q", s[:4])
+	}
+	year = 1 - year
+
+	// Parse with a stand-in year of the same kind, leap or not, so that
+	// time.Parse checks the day of the month against the real year.
+	standIn := "2001"
+	if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+		standIn = "2000"
+	}
+
+	t, err := time.Parse(layout, standIn+s[4:])
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()), nil
+}
@@ -0,0 +1,110 @@
+package pgwire_test
+
+import (
+	"gopsql/pgwire"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBoolText(t *testing.T) {
+	t.Parallel()
+
+	v, err := pgwire.ParseBoolText("t")
+	require.NoError(t, err)
+	require.True(t, v)
+
+	v, err = pgwire.ParseBoolText("f")
+	require.NoError(t, err)
+	require.False(t, v)
+
+	for _, s := range []string{"", "true", "T", "1"} {
+		_, err = pgwire.ParseBoolText(s)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat, s)
+	}
+}
+
+func TestParseDateText(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]time.Time{
+		"2024-02-29":    time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"0001-01-01":    time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"0001-01-01 BC": time.Date(0, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"0044-03-15 BC": time.Date(-43, time.March, 15, 0, 0, 0, 0, time.UTC),
+		"0001-02-29 BC": time.Date(0, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"0005-02-29 BC": time.Date(-4, time.February, 29, 0, 0, 0, 0, time.UTC),
+	}
+
+	for s, want := range tests {
+		got, err := pgwire.ParseDateText(s)
+		require.NoError(t, err, s)
+		require.True(t, want.Equal(got), "%s: got %v", s, got)
+	}
+
+	for _, s := range []string{"", "2023-02-29", "24-01-01", "2024/01/01", "infinity", "0002-02-29 BC", "0000-01-01 BC"} {
+		_, err := pgwire.ParseDateText(s)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat, s)
+	}
+}
+
+func TestParseTimestamptzText(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]time.Time{
+		"2024-01-02 15:04:05+00":        time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC),
+		"2024-01-02 15:04:05.123456-07": time.Date(2024, time.January, 2, 22, 4, 5, 123456000, time.UTC),
+		"2024-01-02 15:04:05.5+05:30":   time.Date(2024, time.January, 2, 9, 34, 5, 500000000, time.UTC),
+		"1900-01-01 00:00:00+00:19:32":  time.Date(1899, time.December, 31, 23, 40, 28, 0, time.UTC),
+		"0001-01-01 00:00:00+00 BC":     time.Date(0, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"0001-02-29 12:00:00+02 BC":     time.Date(0, time.February, 29, 10, 0, 0, 0, time.UTC),
+	}
+
+	for s, want := range tests {
+		got, err := pgwire.ParseTimestamptzText(s)
+		require.NoError(t, err, s)
+		require.True(t, want.Equal(got), "%s: got %v", s, got)
+	}
+
+	for _, s := range []string{"", "2024-01-02", "2024-01-02 15:04:05", "2024-01-02T15:04:05Z"} {
+		_, err := pgwire.ParseTimestamptzText(s)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat, s)
+	}
+}
+
+func TestParseArrayText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		s     string
+		want  []string
+		nulls []bool
+	}{
+		{`{1,2,3}`, []string{"1", "2", "3"}, []bool{false, false, false}},
+		{`{"a","b,c",NULL}`, []string{"a", "b,c", ""}, []bool{false, false, true}},
+		{`{}`, []string{}, []bool{}},
+		{`{""}`, []string{""}, []bool{false}},
+		{`{"NULL",null}`, []string{"NULL", ""}, []bool{false, true}},
+		{`{"say \"hi\"","back\\slash"}`, []string{`say "hi"`, `back\slash`}, []bool{false, false}},
+		{`{ a b , c }`, []string{"a b", "c"}, []bool{false, false}},
+		{`{a\ }`, []string{"a "}, []bool{false}},
+		{`{N\ULL}`, []string{"NULL"}, []bool{false}},
+	}
+
+	for _, tt := range tests {
+		got, err := pgwire.ParseArrayText(tt.s)
+		require.NoError(t, err, tt.s)
+		require.Equal(t, tt.want, got, tt.s)
+
+		got, nulls, err := pgwire.ParseArrayTextNulls(tt.s)
+		require.NoError(t, err, tt.s)
+		require.Equal(t, tt.want, got, tt.s)
+		require.Equal(t, tt.nulls, nulls, tt.s)
+	}
+
+	for _, s := range []string{"", "1,2", "{1,2", `{"a}`, `{a,}`, `{,a}`, `{"a"b}`, `{{1,2},{3,4}}`, `{a"b}`, `{a\}`} {
+		_, err := pgwire.ParseArrayText(s)
+		require.ErrorIs(t, err, pgwire.ErrInvalidFormat, s)
+	}
+}
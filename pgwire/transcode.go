@@ -0,0 +1,67 @@
+package pgwire
+
+func (x *MessageReader) transcode(m Backend) error {
+	var err error
+
+	switch m := m.(type) {
+	case *MsgCommandComplete:
+		m.Tag, err = x.decodeString(m.Tag)
+	case *MsgDataRow:
+		for i, column := range m.Columns {
+			if column == nil || !x.isText(i) {
+				continue
+			}
+
+			m.Columns[i], err = x.decoder.Bytes(column)
+			if err != nil {
+				break
+			}
+		}
+	case *MsgErrorResponse:
+		err = x.decodeStrings(m.Values)
+	case *MsgNoticeResponse:
+		err = x.decodeStrings(m.Values)
+	case *MsgNotificationResponse:
+		m.Channel, err = x.decodeString(m.Channel)
+		if err == nil {
+			m.Payload, err = x.decodeString(m.Payload)
+		}
+	case *MsgParameterStatus:
+		m.Name, err = x.decodeString(m.Name)
+		if err == nil {
+			m.Value, err = x.decodeString(m.Value)
+		}
+	case *MsgRowDescription:
+		err = x.decodeStrings(m.Names)
+	}
+
+	if err != nil {
+		return invalidFormat(err)
+	}
+	return nil
+}
+
+// isText reports whether column is known to be in the text format. A column
+// of unknown format, as in the rows of an Execute without a portal Describe,
+// is left as it is.
+func (x *MessageReader) isText(column int) bool {
+	if column >= len(x.formats) {
+		return false
+	}
+	return FormatKind(x.formats[column]) == FormatKindText
+}
+
+func (x *MessageReader) decodeString(s string) (string, error) {
+	return x.decoder.String(s)
+}
+
+func (x *MessageReader) decodeStrings(values []string) error {
+	for i, value := range values {
+		decoded, err := x.decoder.String(value)
+		if err != nil {
+			return err
+		}
+		values[i] = decoded
+	}
+	return nil
+}